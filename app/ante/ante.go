@@ -0,0 +1,80 @@
+package ante
+
+import (
+	errorsmod "cosmossdk.io/errors"
+	storetypes "cosmossdk.io/store/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+
+	ibcante "github.com/cosmos/ibc-go/v8/modules/core/ante"
+	ibckeeper "github.com/cosmos/ibc-go/v8/modules/core/keeper"
+)
+
+// TransientStoreKey is the name of the transient store tracking the fee-free
+// txs sent by each account in the current block.
+const TransientStoreKey = "transient_ante"
+
+// HandlerOptions are the options required for constructing the RPS AnteHandler.
+type HandlerOptions struct {
+	ante.HandlerOptions
+
+	ParamsKeeper ParamsKeeper
+	StoreKey     storetypes.StoreKey
+	IBCKeeper    *ibckeeper.Keeper
+}
+
+// NewAnteHandler returns the SDK default AnteHandler extended with the RPS
+// fee rules: txs are limited in number of messages and must pay the minimum
+// fee of each of their messages, unless they are fee-free game messages
+// within the per account block quota.
+func NewAnteHandler(options HandlerOptions) (sdk.AnteHandler, error) {
+	if options.AccountKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "account keeper is required for ante builder")
+	}
+
+	if options.BankKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "bank keeper is required for ante builder")
+	}
+
+	if options.SignModeHandler == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "sign mode handler is required for ante builder")
+	}
+
+	if options.ParamsKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "params keeper is required for ante builder")
+	}
+
+	if options.StoreKey == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "transient store key is required for ante builder")
+	}
+
+	if options.IBCKeeper == nil {
+		return nil, errorsmod.Wrap(sdkerrors.ErrLogic, "IBC keeper is required for ante builder")
+	}
+
+	if options.TxFeeChecker == nil {
+		options.TxFeeChecker = NewTxFeeChecker(options.ParamsKeeper, options.StoreKey)
+	}
+
+	anteDecorators := []sdk.AnteDecorator{
+		NewRejectionMetricsDecorator(),  // wraps the whole chain to count its rejections
+		ante.NewSetUpContextDecorator(), // SetUpContext must be called before the other decorators
+		NewMaxMsgsDecorator(options.ParamsKeeper),
+		ante.NewExtensionOptionsDecorator(options.ExtensionOptionChecker),
+		ante.NewValidateBasicDecorator(),
+		ante.NewTxTimeoutHeightDecorator(),
+		ante.NewValidateMemoDecorator(options.AccountKeeper),
+		ante.NewConsumeGasForTxSizeDecorator(options.AccountKeeper),
+		ante.NewDeductFeeDecorator(options.AccountKeeper, options.BankKeeper, options.FeegrantKeeper, options.TxFeeChecker),
+		ante.NewSetPubKeyDecorator(options.AccountKeeper), // SetPubKeyDecorator must be called before all signature verification decorators
+		ante.NewValidateSigCountDecorator(options.AccountKeeper),
+		ante.NewSigGasConsumeDecorator(options.AccountKeeper, options.SigGasConsumer),
+		ante.NewSigVerificationDecorator(options.AccountKeeper, options.SignModeHandler),
+		ante.NewIncrementSequenceDecorator(options.AccountKeeper),
+		ibcante.NewRedundantRelayDecorator(options.IBCKeeper), // rejects the txs only relaying packets already received
+	}
+
+	return sdk.ChainAnteDecorators(anteDecorators...), nil
+}
@@ -0,0 +1,109 @@
+package ante
+
+import (
+	"encoding/binary"
+	"math"
+
+	errorsmod "cosmossdk.io/errors"
+	sdkmath "cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+)
+
+// NewTxFeeChecker returns the ante.TxFeeChecker enforcing the per message
+// type minimum fees of the params. Txs only made of fee-free messages which
+// pay no fee are accepted while their fee payer has quota left in the block,
+// the quota being tracked in the transient store of storeKey.
+// On CheckTx the validator minimum gas prices are enforced as well.
+func NewTxFeeChecker(paramsKeeper ParamsKeeper, storeKey storetypes.StoreKey) ante.TxFeeChecker {
+	return func(ctx sdk.Context, tx sdk.Tx) (sdk.Coins, int64, error) {
+		feeTx, ok := tx.(sdk.FeeTx)
+		if !ok {
+			return nil, 0, errorsmod.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+		}
+
+		feeCoins := feeTx.GetFee()
+		gas := feeTx.GetGas()
+
+		// genesis transactions are free
+		if ctx.BlockHeight() == 0 {
+			return feeCoins, 0, nil
+		}
+
+		params := paramsKeeper.GetParams(ctx)
+		msgs := tx.GetMsgs()
+
+		if feeCoins.IsZero() && params.IsFree(msgs) {
+			used := getFreeTxs(ctx, storeKey, feeTx.FeePayer())
+			if used < params.FreeTxsPerBlock {
+				setFreeTxs(ctx, storeKey, feeTx.FeePayer(), used+1)
+				return feeCoins, 0, nil
+			}
+		}
+
+		if minFee := params.MinFee(msgs); !feeCoins.IsAllGTE(minFee) {
+			return nil, 0, errorsmod.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fees for the tx messages; got: %s required: %s", feeCoins, minFee)
+		}
+
+		// Ensure that the provided fees meet a minimum threshold for the validator.
+		// This is only for local mempool purposes, and thus is only ran on check tx.
+		if ctx.IsCheckTx() {
+			if minGasPrices := ctx.MinGasPrices(); !minGasPrices.IsZero() {
+				requiredFees := make(sdk.Coins, len(minGasPrices))
+
+				// fee = ceil(minGasPrice * gasLimit)
+				glDec := sdkmath.LegacyNewDec(int64(gas))
+				for i, gp := range minGasPrices {
+					requiredFees[i] = sdk.NewCoin(gp.Denom, gp.Amount.Mul(glDec).Ceil().RoundInt())
+				}
+
+				if !feeCoins.IsAnyGTE(requiredFees) {
+					return nil, 0, errorsmod.Wrapf(sdkerrors.ErrInsufficientFee, "insufficient fees; got: %s required: %s", feeCoins, requiredFees)
+				}
+			}
+		}
+
+		return feeCoins, getTxPriority(feeCoins, int64(gas)), nil
+	}
+}
+
+// getTxPriority returns the tx priority as the smallest gas price of the fee
+// denominations, like the SDK default fee checker.
+func getTxPriority(fee sdk.Coins, gas int64) int64 {
+	if gas <= 0 {
+		return 0
+	}
+
+	var priority int64
+	for _, c := range fee {
+		p := int64(math.MaxInt64)
+		gasPrice := c.Amount.QuoRaw(gas)
+		if gasPrice.IsInt64() {
+			p = gasPrice.Int64()
+		}
+		if priority == 0 || p < priority {
+			priority = p
+		}
+	}
+
+	return priority
+}
+
+// getFreeTxs returns the number of fee-free txs sent by addr in the current block.
+func getFreeTxs(ctx sdk.Context, storeKey storetypes.StoreKey, addr []byte) uint32 {
+	bz := ctx.TransientStore(storeKey).Get(addr)
+	if bz == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(bz)
+}
+
+func setFreeTxs(ctx sdk.Context, storeKey storetypes.StoreKey, addr []byte, count uint32) {
+	bz := make([]byte, 4)
+	binary.BigEndian.PutUint32(bz, count)
+	ctx.TransientStore(storeKey).Set(addr, bz)
+}
@@ -0,0 +1,197 @@
+package ante_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectestutil "github.com/cosmos/cosmos-sdk/codec/testutil"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/0xlb/rps-chain/app/ante"
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+var (
+	alice = sdk.AccAddress("alice_______________")
+	bob   = sdk.AccAddress("bob_________________")
+)
+
+func rps(amount int64) sdk.Coins {
+	return sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, amount))
+}
+
+// feeTestSuite runs the fee checker against a context whose transient store
+// is reset on every new block.
+type feeTestSuite struct {
+	t        *testing.T
+	txConfig client.TxConfig
+	testCtx  testutil.TestContext
+	tkey     *storetypes.TransientStoreKey
+	checker  func(sdk.Context, sdk.Tx) (sdk.Coins, int64, error)
+}
+
+func newFeeTestSuite(t *testing.T, p ante.Params) *feeTestSuite {
+	interfaceRegistry := codectestutil.CodecOptions{
+		AccAddressPrefix: params.Bech32PrefixAccAddr,
+		ValAddressPrefix: params.Bech32PrefixValAddr,
+	}.NewInterfaceRegistry()
+	banktypes.RegisterInterfaces(interfaceRegistry)
+
+	tkey := storetypes.NewTransientStoreKey(ante.TransientStoreKey)
+	testCtx := testutil.DefaultContextWithDB(t, storetypes.NewKVStoreKey("test"), tkey)
+
+	return &feeTestSuite{
+		t:        t,
+		txConfig: authtx.NewTxConfig(codec.NewProtoCodec(interfaceRegistry), authtx.DefaultSignModes),
+		testCtx:  testCtx,
+		tkey:     tkey,
+		checker:  ante.NewTxFeeChecker(ante.StaticParamsKeeper{Params: p}, tkey),
+	}
+}
+
+// ctx returns the context of a block at height 1.
+func (s *feeTestSuite) ctx() sdk.Context {
+	return s.testCtx.Ctx.WithBlockHeight(1)
+}
+
+// nextBlock commits the store, which resets the transient store.
+func (s *feeTestSuite) nextBlock() {
+	s.testCtx.CMS.Commit()
+}
+
+func (s *feeTestSuite) tx(payer sdk.AccAddress, fee sdk.Coins, msgs ...sdk.Msg) sdk.Tx {
+	txBuilder := s.txConfig.NewTxBuilder()
+	require.NoError(s.t, txBuilder.SetMsgs(msgs...))
+	txBuilder.SetFeeAmount(fee)
+	txBuilder.SetGasLimit(200_000)
+	txBuilder.SetFeePayer(payer)
+
+	return txBuilder.GetTx()
+}
+
+func send(from sdk.AccAddress) sdk.Msg {
+	return banktypes.NewMsgSend(from, bob, rps(1))
+}
+
+func multiSend(from sdk.AccAddress) sdk.Msg {
+	return banktypes.NewMsgMultiSend(banktypes.NewInput(from, rps(1)), []banktypes.Output{banktypes.NewOutput(bob, rps(1))})
+}
+
+func TestTxFeeCheckerMinFees(t *testing.T) {
+	s := newFeeTestSuite(t, ante.DefaultParams())
+
+	testCases := []struct {
+		name  string
+		fee   sdk.Coins
+		msgs  []sdk.Msg
+		valid bool
+	}{
+		{"default min fee", rps(1), []sdk.Msg{send(alice)}, true},
+		{"no fee", nil, []sdk.Msg{send(alice)}, false},
+		{"fee of another denom", sdk.NewCoins(sdk.NewInt64Coin("atom", 10)), []sdk.Msg{send(alice)}, false},
+		{"fee above the min fee", rps(100), []sdk.Msg{send(alice)}, true},
+		{"min fee of the msg type", rps(5), []sdk.Msg{multiSend(alice)}, true},
+		{"default min fee for a msg type with its own", rps(1), []sdk.Msg{multiSend(alice)}, false},
+		{"min fees of every msg", rps(7), []sdk.Msg{send(alice), multiSend(alice), send(alice)}, true},
+		{"min fee of a single msg", rps(6), []sdk.Msg{send(alice), multiSend(alice), send(alice)}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fee, _, err := s.checker(s.ctx(), s.tx(alice, tc.fee, tc.msgs...))
+			if !tc.valid {
+				require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+}
+
+func TestTxFeeCheckerGenesis(t *testing.T) {
+	s := newFeeTestSuite(t, ante.DefaultParams())
+
+	// gentxs are free
+	_, priority, err := s.checker(s.testCtx.Ctx.WithBlockHeight(0), s.tx(alice, nil, send(alice)))
+	require.NoError(t, err)
+	require.Zero(t, priority)
+}
+
+func TestTxFeeCheckerMinGasPrices(t *testing.T) {
+	s := newFeeTestSuite(t, ante.DefaultParams())
+
+	// the 200000 gas of the tx require 2000rps at 0.01rps per gas
+	ctx := s.ctx().WithIsCheckTx(true).WithMinGasPrices(sdk.NewDecCoins(sdk.NewDecCoinFromDec(params.DefaultBondDenom, math.LegacyMustNewDecFromStr("0.01"))))
+
+	_, _, err := s.checker(ctx, s.tx(alice, rps(1), send(alice)))
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+
+	_, priority, err := s.checker(ctx, s.tx(alice, rps(4000), send(alice)))
+	require.NoError(t, err)
+	require.Equal(t, int64(4000/200_000), priority)
+
+	// the validator min gas prices only apply to CheckTx
+	_, _, err = s.checker(ctx.WithIsCheckTx(false), s.tx(alice, rps(1), send(alice)))
+	require.NoError(t, err)
+}
+
+func TestTxFeeCheckerFreeQuota(t *testing.T) {
+	p := ante.DefaultParams()
+	p.FreeMsgTypes = []string{sdk.MsgTypeURL(&banktypes.MsgSend{})}
+	p.FreeTxsPerBlock = 2
+	s := newFeeTestSuite(t, p)
+
+	// alice sends her two free txs of the block
+	for i := 0; i < 2; i++ {
+		_, priority, err := s.checker(s.ctx(), s.tx(alice, nil, send(alice)))
+		require.NoError(t, err)
+		require.Zero(t, priority)
+	}
+
+	// the quota is tracked in the transient store
+	require.NotNil(t, s.ctx().TransientStore(s.tkey).Get(alice))
+	require.Nil(t, s.ctx().TransientStore(s.tkey).Get(bob))
+
+	_, _, err := s.checker(s.ctx(), s.tx(alice, nil, send(alice)))
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+
+	// paying txs don't need any quota
+	_, _, err = s.checker(s.ctx(), s.tx(alice, rps(1), send(alice)))
+	require.NoError(t, err)
+
+	// the quota is per fee payer
+	_, _, err = s.checker(s.ctx(), s.tx(bob, nil, send(bob)))
+	require.NoError(t, err)
+
+	// a tx mixing free and non-free msgs pays the min fee of all its msgs
+	_, _, err = s.checker(s.ctx(), s.tx(bob, nil, send(bob), multiSend(bob)))
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+
+	// the quota is reset on the next block
+	s.nextBlock()
+	require.Nil(t, s.ctx().TransientStore(s.tkey).Get(alice))
+
+	_, _, err = s.checker(s.ctx(), s.tx(alice, nil, send(alice)))
+	require.NoError(t, err)
+}
+
+func TestTxFeeCheckerNoFreeMsgTypes(t *testing.T) {
+	s := newFeeTestSuite(t, ante.DefaultParams())
+
+	// no msg type is fee-free by default
+	_, _, err := s.checker(s.ctx(), s.tx(alice, nil, send(alice)))
+	require.ErrorIs(t, err, sdkerrors.ErrInsufficientFee)
+	require.Nil(t, s.ctx().TransientStore(s.tkey).Get(alice))
+}
@@ -0,0 +1,49 @@
+package ante
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/go-metrics"
+
+	errorsmod "cosmossdk.io/errors"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RejectionMetricsDecorator counts the txs rejected by the rest of the ante
+// chain, labelled by execution mode and error. Simulations aren't counted.
+type RejectionMetricsDecorator struct{}
+
+// NewRejectionMetricsDecorator returns a new RejectionMetricsDecorator.
+func NewRejectionMetricsDecorator() RejectionMetricsDecorator {
+	return RejectionMetricsDecorator{}
+}
+
+func (RejectionMetricsDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	newCtx, err := next(ctx, tx, simulate)
+	if err == nil || simulate {
+		return newCtx, err
+	}
+
+	mode := "deliver"
+	switch {
+	case ctx.IsReCheckTx():
+		mode = "recheck"
+	case ctx.IsCheckTx():
+		mode = "check"
+	}
+
+	codespace, code, _ := errorsmod.ABCIInfo(err, false)
+	telemetry.IncrCounterWithLabels(
+		[]string{"rps", "ante", "rejections"},
+		1,
+		[]metrics.Label{
+			telemetry.NewLabel("mode", mode),
+			telemetry.NewLabel("codespace", codespace),
+			telemetry.NewLabel("code", strconv.FormatUint(uint64(code), 10)),
+		},
+	)
+
+	return newCtx, err
+}
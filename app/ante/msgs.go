@@ -0,0 +1,28 @@
+package ante
+
+import (
+	errorsmod "cosmossdk.io/errors"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+// MaxMsgsDecorator rejects txs containing more messages than allowed by the params.
+type MaxMsgsDecorator struct {
+	paramsKeeper ParamsKeeper
+}
+
+func NewMaxMsgsDecorator(pk ParamsKeeper) MaxMsgsDecorator {
+	return MaxMsgsDecorator{
+		paramsKeeper: pk,
+	}
+}
+
+func (mmd MaxMsgsDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	maxMsgs := mmd.paramsKeeper.GetParams(ctx).MaxMsgs
+	if n := len(tx.GetMsgs()); n > maxMsgs {
+		return ctx, errorsmod.Wrapf(sdkerrors.ErrInvalidRequest, "too many messages; got: %d, max: %d", n, maxMsgs)
+	}
+
+	return next(ctx, tx, simulate)
+}
@@ -0,0 +1,27 @@
+package ante_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"github.com/0xlb/rps-chain/app/ante"
+)
+
+func TestMaxMsgsDecorator(t *testing.T) {
+	p := ante.DefaultParams()
+	p.MaxMsgs = 2
+	s := newFeeTestSuite(t, p)
+
+	anteHandler := sdk.ChainAnteDecorators(ante.NewMaxMsgsDecorator(ante.StaticParamsKeeper{Params: p}))
+
+	_, err := anteHandler(s.ctx(), s.tx(alice, rps(2), send(alice), send(alice)), false)
+	require.NoError(t, err)
+
+	_, err = anteHandler(s.ctx(), s.tx(alice, rps(3), send(alice), send(alice), send(alice)), false)
+	require.ErrorIs(t, err, sdkerrors.ErrInvalidRequest)
+	require.ErrorContains(t, err, "too many messages; got: 3, max: 2")
+}
@@ -0,0 +1,123 @@
+package ante
+
+import (
+	"errors"
+	"fmt"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// ParamsKeeper provides the fee rules enforced by the ante handler. No module
+// stores them yet, so the app uses a StaticParamsKeeper of DefaultParams.
+type ParamsKeeper interface {
+	GetParams(ctx sdk.Context) Params
+}
+
+// Params defines the fee rules enforced by the ante handler.
+type Params struct {
+	// MaxMsgs is the maximum number of messages a tx can contain.
+	MaxMsgs int
+	// DefaultMinFee is the minimum fee paid for each message whose type is not
+	// present in MinFees.
+	DefaultMinFee sdk.Coins
+	// MinFees maps a message type URL to the minimum fee paid for each message
+	// of that type.
+	MinFees map[string]sdk.Coins
+	// FreeMsgTypes are the type URLs of the low value messages which can be
+	// sent without fee by txs only made of them. It is meant for the game
+	// messages, so none are free until the game module lands.
+	FreeMsgTypes []string
+	// FreeTxsPerBlock is the number of fee-free txs an account can send per block.
+	FreeTxsPerBlock uint32
+}
+
+// DefaultParams returns the fee rules of the chain. They are compiled in and
+// changing them requires a software upgrade.
+func DefaultParams() Params {
+	return Params{
+		MaxMsgs:       32,
+		DefaultMinFee: sdk.NewCoins(sdk.NewCoin(params.DefaultBondDenom, math.NewInt(1))),
+		MinFees: map[string]sdk.Coins{
+			sdk.MsgTypeURL(&banktypes.MsgMultiSend{}):          sdk.NewCoins(sdk.NewCoin(params.DefaultBondDenom, math.NewInt(5))),
+			sdk.MsgTypeURL(&stakingtypes.MsgCreateValidator{}): sdk.NewCoins(sdk.NewCoin(params.DefaultBondDenom, math.NewInt(100))),
+		},
+		FreeMsgTypes:    []string{},
+		FreeTxsPerBlock: 5,
+	}
+}
+
+// Validate performs a basic validation of the fee rules.
+func (p Params) Validate() error {
+	if p.MaxMsgs <= 0 {
+		return errors.New("max msgs must be positive")
+	}
+
+	if err := p.DefaultMinFee.Validate(); err != nil {
+		return fmt.Errorf("invalid default min fee: %w", err)
+	}
+
+	for typeURL, fee := range p.MinFees {
+		if err := fee.Validate(); err != nil {
+			return fmt.Errorf("invalid min fee of %s: %w", typeURL, err)
+		}
+	}
+
+	return nil
+}
+
+// MinFee returns the minimum fee of a tx made of msgs.
+func (p Params) MinFee(msgs []sdk.Msg) sdk.Coins {
+	minFee := sdk.NewCoins()
+	for _, msg := range msgs {
+		fee, ok := p.MinFees[sdk.MsgTypeURL(msg)]
+		if !ok {
+			fee = p.DefaultMinFee
+		}
+
+		minFee = minFee.Add(fee...)
+	}
+
+	return minFee
+}
+
+// IsFree returns whether msgs are only made of fee-free message types.
+func (p Params) IsFree(msgs []sdk.Msg) bool {
+	if len(msgs) == 0 || p.FreeTxsPerBlock == 0 {
+		return false
+	}
+
+	for _, msg := range msgs {
+		if !p.isFreeMsgType(sdk.MsgTypeURL(msg)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p Params) isFreeMsgType(typeURL string) bool {
+	for _, t := range p.FreeMsgTypes {
+		if t == typeURL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StaticParamsKeeper is a ParamsKeeper always returning the same fee rules,
+// i.e. compiled in rules.
+type StaticParamsKeeper struct {
+	Params Params
+}
+
+// GetParams implements ParamsKeeper.
+func (k StaticParamsKeeper) GetParams(_ sdk.Context) Params {
+	return k.Params
+}
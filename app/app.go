@@ -7,11 +7,13 @@ import (
 	"path/filepath"
 
 	dbm "github.com/cosmos/cosmos-db"
+	"github.com/spf13/cast"
 
 	"cosmossdk.io/core/appconfig"
 	"cosmossdk.io/depinject"
 	"cosmossdk.io/log"
 	storetypes "cosmossdk.io/store/types"
+	upgradekeeper "cosmossdk.io/x/upgrade/keeper"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
 	"github.com/cosmos/cosmos-sdk/client"
@@ -23,20 +25,36 @@ import (
 	"github.com/cosmos/cosmos-sdk/server/config"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
+	authante "github.com/cosmos/cosmos-sdk/x/auth/ante"
 	authkeeper "github.com/cosmos/cosmos-sdk/x/auth/keeper"
 	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
 	consensuskeeper "github.com/cosmos/cosmos-sdk/x/consensus/keeper"
+	crisiskeeper "github.com/cosmos/cosmos-sdk/x/crisis/keeper"
 	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
 	"github.com/cosmos/cosmos-sdk/x/genutil"
 	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	govkeeper "github.com/cosmos/cosmos-sdk/x/gov/keeper"
+	groupkeeper "github.com/cosmos/cosmos-sdk/x/group/keeper"
+	mintkeeper "github.com/cosmos/cosmos-sdk/x/mint/keeper"
 	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
 
+	capabilitykeeper "github.com/cosmos/ibc-go/modules/capability/keeper"
+	ibctransferkeeper "github.com/cosmos/ibc-go/v8/modules/apps/transfer/keeper"
+	ibckeeper "github.com/cosmos/ibc-go/v8/modules/core/keeper"
+
+	"github.com/0xlb/rps-chain/app/ante"
+	"github.com/0xlb/rps-chain/app/indexer"
+
 	_ "cosmossdk.io/api/cosmos/tx/config/v1"          // import for side-effects
+	_ "cosmossdk.io/x/upgrade"                        // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/auth"           // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/auth/tx/config" // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/bank"           // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/consensus"      // import for side-effects
+	_ "github.com/cosmos/cosmos-sdk/x/crisis"         // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/distribution"   // import for side-effects
+	_ "github.com/cosmos/cosmos-sdk/x/gov"            // import for side-effects
+	_ "github.com/cosmos/cosmos-sdk/x/group/module"   // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/mint"           // import for side-effects
 	_ "github.com/cosmos/cosmos-sdk/x/staking"        // import for side-effects
 )
@@ -61,14 +79,29 @@ type RPSApp struct {
 	appCodec          codec.Codec
 	txConfig          client.TxConfig
 	interfaceRegistry codectypes.InterfaceRegistry
+	rpsConfig         RPSConfig
+	indexer           *indexer.Indexer
+	telemetryEnabled  bool
 
 	// keepers
 	AccountKeeper         authkeeper.AccountKeeper
 	BankKeeper            bankkeeper.Keeper
 	StakingKeeper         *stakingkeeper.Keeper
 	DistrKeeper           distrkeeper.Keeper
+	MintKeeper            mintkeeper.Keeper
+	GovKeeper             *govkeeper.Keeper
+	UpgradeKeeper         *upgradekeeper.Keeper
+	CrisisKeeper          *crisiskeeper.Keeper
+	GroupKeeper           groupkeeper.Keeper
 	ConsensusParamsKeeper consensuskeeper.Keeper
 
+	// IBC keepers, which don't support app wiring, see registerIBCModules
+	CapabilityKeeper     *capabilitykeeper.Keeper
+	IBCKeeper            *ibckeeper.Keeper
+	TransferKeeper       ibctransferkeeper.Keeper
+	ScopedIBCKeeper      capabilitykeeper.ScopedKeeper
+	ScopedTransferKeeper capabilitykeeper.ScopedKeeper
+
 	// simulation manager
 	sm *module.SimulationManager
 }
@@ -92,6 +125,10 @@ func AppConfig() depinject.Config {
 				genutiltypes.ModuleName: genutil.NewAppModuleBasic(genutiltypes.DefaultMessageValidator),
 			},
 		),
+		depinject.Provide(
+			// supply the rps halving schedule to x/mint
+			ProvideInflationCalculationFn,
+		),
 	)
 }
 
@@ -107,8 +144,17 @@ func NewRPSApp(
 	var (
 		app        = &RPSApp{}
 		appBuilder *runtime.AppBuilder
+		err        error
 	)
 
+	// read and validate the [rps] section of app.toml
+	app.rpsConfig, err = ReadRPSConfig(appOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	app.telemetryEnabled = cast.ToBool(appOpts.Get(flagTelemetryEnabled))
+
 	if err := depinject.Inject(
 		depinject.Configs(
 			AppConfig(),
@@ -126,6 +172,11 @@ func NewRPSApp(
 		&app.BankKeeper,
 		&app.StakingKeeper,
 		&app.DistrKeeper,
+		&app.MintKeeper,
+		&app.GovKeeper,
+		&app.UpgradeKeeper,
+		&app.CrisisKeeper,
+		&app.GroupKeeper,
 		&app.ConsensusParamsKeeper,
 	); err != nil {
 		return nil, err
@@ -133,13 +184,66 @@ func NewRPSApp(
 
 	app.App = appBuilder.Build(db, traceStore, baseAppOptions...)
 
+	// register the IBC modules before the ante handler, which uses their keeper
+	if err := app.registerIBCModules(); err != nil {
+		return nil, err
+	}
+
+	// register the transient store tracking the fee-free txs of the ante handler
+	anteStoreKey := storetypes.NewTransientStoreKey(ante.TransientStoreKey)
+	if err := app.RegisterStores(anteStoreKey); err != nil {
+		return nil, err
+	}
+
+	// set the custom ante handler enforcing the rps fee rules
+	anteHandler, err := ante.NewAnteHandler(ante.HandlerOptions{
+		HandlerOptions: authante.HandlerOptions{
+			AccountKeeper:   app.AccountKeeper,
+			BankKeeper:      app.BankKeeper,
+			SignModeHandler: app.txConfig.SignModeHandler(),
+		},
+		ParamsKeeper: ante.StaticParamsKeeper{Params: ante.DefaultParams()},
+		StoreKey:     anteStoreKey,
+		IBCKeeper:    app.IBCKeeper,
+	})
+	if err != nil {
+		return nil, err
+	}
+	app.SetAnteHandler(anteHandler)
+
+	// order the mempool by gas price, time sensitive txs first, and build the
+	// block proposals following that order, unless mempool.max-txs disables it
+	mempool := NewMempool(appOpts)
+	app.SetMempool(mempool)
+
+	proposalHandler := baseapp.NewDefaultProposalHandler(mempool, app.App.BaseApp)
+	app.SetPrepareProposal(proposalHandler.PrepareProposalHandler())
+	app.SetProcessProposal(proposalHandler.ProcessProposalHandler())
+
+	// register the upgrade handlers, the store loader of the upgrade to apply
+	// and the PreBlocker applying the upgrades at a compiled-in height
+	if err := app.setUpgradeHandlers(); err != nil {
+		return nil, err
+	}
+
 	// register streaming services
 	if err := app.RegisterStreamingServices(appOpts, app.kvStoreKeys()); err != nil {
 		return nil, err
 	}
 
+	// index the finalized blocks into the local SQLite database
+	if app.rpsConfig.IndexerEnabled {
+		if err := app.registerIndexer(appOpts); err != nil {
+			return nil, err
+		}
+	}
+
 	/****  Module Options ****/
 
+	// register the invariants of the modules, asserted by x/crisis every
+	// inv-check-period blocks
+	app.ModuleManager.RegisterInvariants(app.CrisisKeeper)
+
 	// create the simulation manager and define the order of the modules for deterministic simulations
 	// NOTE: this is not required apps that don't use the simulator for fuzz testing transactions
 	app.sm = module.NewSimulationManagerFromAppModules(app.ModuleManager.Modules, make(map[string]module.AppModuleSimulation, 0))
@@ -157,6 +261,21 @@ func (app *RPSApp) LegacyAmino() *codec.LegacyAmino {
 	return app.legacyAmino
 }
 
+// AppCodec returns RPSApp's app codec.
+func (app *RPSApp) AppCodec() codec.Codec {
+	return app.appCodec
+}
+
+// TxConfig returns RPSApp's TxConfig.
+func (app *RPSApp) TxConfig() client.TxConfig {
+	return app.txConfig
+}
+
+// RPSConfig returns the [rps] section of app.toml the app was created with.
+func (app *RPSApp) RPSConfig() RPSConfig {
+	return app.rpsConfig
+}
+
 // GetKey returns the KVStoreKey for the provided store key.
 func (app *RPSApp) GetKey(storeKey string) *storetypes.KVStoreKey {
 	sk := app.UnsafeFindStoreKey(storeKey)
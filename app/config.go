@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/cast"
+
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+)
+
+const (
+	flagIndexerEnabled = "rps.indexer-enabled"
+)
+
+// RPSConfig defines the [rps] section of app.toml, holding the node settings
+// specific to the RPS chain.
+type RPSConfig struct {
+	// IndexerEnabled enables the built-in indexer of finalized blocks.
+	IndexerEnabled bool `mapstructure:"indexer-enabled"`
+}
+
+// CustomAppConfig is the app.toml configuration of rpsd: the SDK server
+// configuration extended with the [rps] section.
+type CustomAppConfig struct {
+	serverconfig.Config `mapstructure:",squash"`
+
+	RPS RPSConfig `mapstructure:"rps"`
+}
+
+// RPSConfigTemplate is the app.toml template of the [rps] section.
+const RPSConfigTemplate = `
+###############################################################################
+###                             RPS Configuration                           ###
+###############################################################################
+
+[rps]
+
+# Enable the built-in indexer of finalized blocks.
+indexer-enabled = {{ .RPS.IndexerEnabled }}
+`
+
+// DefaultRPSConfig returns the default [rps] section of app.toml.
+func DefaultRPSConfig() RPSConfig {
+	return RPSConfig{
+		IndexerEnabled: false,
+	}
+}
+
+// ReadRPSConfig reads the [rps] section of app.toml from appOpts, using the
+// default of every missing setting. A setting of the wrong type is an error
+// rather than its zero value.
+func ReadRPSConfig(appOpts servertypes.AppOptions) (RPSConfig, error) {
+	cfg := DefaultRPSConfig()
+
+	if v := appOpts.Get(flagIndexerEnabled); v != nil {
+		enabled, err := cast.ToBoolE(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid [rps] section of app.toml: %s: %w", flagIndexerEnabled, err)
+		}
+		cfg.IndexerEnabled = enabled
+	}
+
+	return cfg, nil
+}
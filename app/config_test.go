@@ -0,0 +1,98 @@
+package app_test
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+
+	"github.com/0xlb/rps-chain/app"
+)
+
+// readAppConfig renders the app.toml template of cfg and reads it back as
+// the node does on start.
+func readAppConfig(t *testing.T, cfg app.CustomAppConfig) *viper.Viper {
+	t.Helper()
+
+	tmpl, err := template.New("app.toml").Parse(serverconfig.DefaultConfigTemplate + app.RPSConfigTemplate)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, cfg))
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(&buf))
+
+	return v
+}
+
+func TestRPSConfigTemplate(t *testing.T) {
+	cfg := app.CustomAppConfig{Config: *serverconfig.DefaultConfig(), RPS: app.DefaultRPSConfig()}
+
+	rpsConfig, err := app.ReadRPSConfig(readAppConfig(t, cfg))
+	require.NoError(t, err)
+	require.Equal(t, app.DefaultRPSConfig(), rpsConfig)
+
+	cfg.RPS.IndexerEnabled = true
+
+	rpsConfig, err = app.ReadRPSConfig(readAppConfig(t, cfg))
+	require.NoError(t, err)
+	require.True(t, rpsConfig.IndexerEnabled)
+}
+
+func TestReadRPSConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings map[string]interface{}
+		expected app.RPSConfig
+		errMsg   string
+	}{
+		{
+			name:     "missing settings use the defaults",
+			expected: app.DefaultRPSConfig(),
+		},
+		{
+			name:     "boolean",
+			settings: map[string]interface{}{"rps.indexer-enabled": true},
+			expected: app.RPSConfig{IndexerEnabled: true},
+		},
+		{
+			name:     "boolean string",
+			settings: map[string]interface{}{"rps.indexer-enabled": "true"},
+			expected: app.RPSConfig{IndexerEnabled: true},
+		},
+		{
+			name:     "invalid boolean",
+			settings: map[string]interface{}{"rps.indexer-enabled": "yes"},
+			errMsg:   "invalid [rps] section of app.toml: rps.indexer-enabled",
+		},
+		{
+			name:     "wrong type",
+			settings: map[string]interface{}{"rps.indexer-enabled": []string{"true"}},
+			errMsg:   "invalid [rps] section of app.toml: rps.indexer-enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := viper.New()
+			for key, value := range tc.settings {
+				v.Set(key, value)
+			}
+
+			cfg, err := app.ReadRPSConfig(v)
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cfg)
+		})
+	}
+}
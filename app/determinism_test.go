@@ -0,0 +1,307 @@
+package app_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmted25519 "github.com/cometbft/cometbft/crypto/ed25519"
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app"
+	"github.com/0xlb/rps-chain/app/ante"
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+var (
+	flagDeterminismBlocks = flag.Int("determinism.blocks", 20, "number of blocks of the determinism workload")
+	flagDeterminismTxs    = flag.Int("determinism.txs", 10, "maximum number of txs per block of the determinism workload")
+	flagDeterminismSeed   = flag.Int64("determinism.seed", 0, "seed of the determinism workload, random if 0")
+)
+
+const (
+	determinismChainID  = "rps-determinism"
+	determinismAccounts = 10
+	determinismGas      = 400_000
+)
+
+// determinismNode is an RPSApp executing its blocks with its own GOMAXPROCS.
+type determinismNode struct {
+	app      *app.RPSApp
+	maxProcs int
+}
+
+func newDeterminismNode(t *testing.T, maxProcs int) determinismNode {
+	t.Helper()
+
+	rpsApp, err := app.NewRPSApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, simtestutil.NewAppOptionsWithFlagHome(t.TempDir()), baseapp.SetChainID(determinismChainID))
+	require.NoError(t, err)
+
+	return determinismNode{app: rpsApp, maxProcs: maxProcs}
+}
+
+// finalizeAndCommit executes and commits the block with the GOMAXPROCS of
+// the node, restoring the previous value afterwards.
+func (n determinismNode) finalizeAndCommit(req *abci.RequestFinalizeBlock) (*abci.ResponseFinalizeBlock, error) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(n.maxProcs))
+
+	res, err := n.app.FinalizeBlock(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := n.app.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// TestAppHashDeterminism executes a random workload on two RPSApps running
+// with different GOMAXPROCS and compares their app hashes at every block.
+// Map iteration orders already differ between the two apps since Go
+// randomizes them on every range. The first divergence is reported with the
+// store keys whose values differ.
+//
+// The workload size and seed are set with the -determinism.* flags, e.g.
+//
+//	go test ./app -run TestAppHashDeterminism -determinism.blocks 200 -determinism.seed 42
+func TestAppHashDeterminism(t *testing.T) {
+	seed := *flagDeterminismSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("determinism workload seed: %d", seed)
+	r := rand.New(rand.NewSource(seed))
+
+	nodes := []determinismNode{
+		newDeterminismNode(t, 1),
+		newDeterminismNode(t, runtime.NumCPU()+1),
+	}
+
+	w := newDeterminismWorkload(t, nodes[0].app)
+
+	genesisTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, n := range nodes {
+		_, err := n.app.InitChain(&abci.RequestInitChain{
+			Time:            genesisTime,
+			ChainId:         determinismChainID,
+			ConsensusParams: simtestutil.DefaultConsensusParams,
+			AppStateBytes:   w.appState,
+			InitialHeight:   1,
+		})
+		require.NoError(t, err)
+	}
+
+	for height := int64(1); height <= int64(*flagDeterminismBlocks); height++ {
+		req := &abci.RequestFinalizeBlock{
+			Height:          height,
+			Time:            genesisTime.Add(time.Duration(height) * 5 * time.Second),
+			Txs:             w.nextTxs(r, nodes[0].app),
+			ProposerAddress: w.validator.Address,
+			DecidedLastCommit: abci.CommitInfo{
+				Votes: []abci.VoteInfo{{
+					Validator:   abci.Validator{Address: w.validator.Address, Power: w.validator.VotingPower},
+					BlockIdFlag: cmtproto.BlockIDFlagCommit,
+				}},
+			},
+		}
+
+		var appHashes [][]byte
+		for _, n := range nodes {
+			res, err := n.finalizeAndCommit(req)
+			require.NoError(t, err, "height %d", height)
+			appHashes = append(appHashes, res.AppHash)
+		}
+
+		if !bytes.Equal(appHashes[0], appHashes[1]) {
+			t.Fatalf("app hashes diverged at height %d (seed %d): %X != %X\n%s",
+				height, seed, appHashes[0], appHashes[1], diffApps(nodes[0].app, nodes[1].app))
+		}
+	}
+}
+
+// diffApps describes the store entries differing between the two apps.
+func diffApps(a, b *app.RPSApp) string {
+	var names []string
+	for _, key := range a.GetStoreKeys() {
+		if _, ok := key.(*storetypes.KVStoreKey); ok {
+			names = append(names, key.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var diff bytes.Buffer
+	for _, name := range names {
+		storeA := a.CommitMultiStore().GetKVStore(a.GetKey(name))
+		storeB := b.CommitMultiStore().GetKVStore(b.GetKey(name))
+
+		kvAs, kvBs := simtestutil.DiffKVStores(storeA, storeB, nil)
+		if len(kvAs) == 0 && len(kvBs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&diff, "store %s differs:\n", name)
+		for i := 0; i < len(kvAs) || i < len(kvBs); i++ {
+			switch {
+			case i < len(kvAs) && i < len(kvBs):
+				fmt.Fprintf(&diff, "  key %X: %X != %X\n", kvAs[i].Key, kvAs[i].Value, kvBs[i].Value)
+			case i < len(kvAs):
+				fmt.Fprintf(&diff, "  key %X only in first app: %X\n", kvAs[i].Key, kvAs[i].Value)
+			default:
+				fmt.Fprintf(&diff, "  key %X only in second app: %X\n", kvBs[i].Key, kvBs[i].Value)
+			}
+		}
+	}
+
+	return diff.String()
+}
+
+// determinismWorkload generates random txs of funded accounts delegating to
+// a single validator.
+type determinismWorkload struct {
+	t         *testing.T
+	appState  []byte
+	validator *cmttypes.Validator
+	accounts  []*secp256k1.PrivKey
+}
+
+func newDeterminismWorkload(t *testing.T, rpsApp *app.RPSApp) *determinismWorkload {
+	t.Helper()
+
+	w := &determinismWorkload{t: t}
+
+	valKey := cmted25519.GenPrivKeyFromSecret([]byte("validator"))
+	w.validator = cmttypes.NewValidator(valKey.PubKey(), 1)
+	valSet := cmttypes.NewValidatorSet([]*cmttypes.Validator{w.validator})
+
+	var (
+		genAccounts []authtypes.GenesisAccount
+		balances    []banktypes.Balance
+	)
+	for i := 0; i < determinismAccounts; i++ {
+		key := secp256k1.GenPrivKeyFromSecret([]byte(fmt.Sprintf("account%d", i)))
+		w.accounts = append(w.accounts, key)
+
+		addr := sdk.AccAddress(key.PubKey().Address())
+		genAccounts = append(genAccounts, authtypes.NewBaseAccount(addr, key.PubKey(), uint64(i), 0))
+		balances = append(balances, banktypes.Balance{
+			Address: addr.String(),
+			Coins:   sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, 1_000_000_000)),
+		})
+	}
+
+	genesisState, err := simtestutil.GenesisStateWithValSet(rpsApp.AppCodec(), rpsApp.DefaultGenesis(), valSet, genAccounts, balances...)
+	require.NoError(t, err)
+
+	// the module default genesis states and the bonded tokens of the
+	// validator use the bond denom of the sdk
+	require.NoError(t, app.SetDenomGenesis(rpsApp.AppCodec(), genesisState))
+	bankGenState := banktypes.GetGenesisStateFromAppState(rpsApp.AppCodec(), genesisState)
+	for i, balance := range bankGenState.Balances {
+		bankGenState.Balances[i].Coins = withRPSDenom(balance.Coins)
+	}
+	bankGenState.Supply = withRPSDenom(bankGenState.Supply)
+	genesisState[banktypes.ModuleName] = rpsApp.AppCodec().MustMarshalJSON(bankGenState)
+
+	w.appState, err = cmtjson.MarshalIndent(genesisState, "", " ")
+	require.NoError(t, err)
+
+	return w
+}
+
+// withRPSDenom returns coins with the bond denom of the sdk converted to rps.
+func withRPSDenom(coins sdk.Coins) sdk.Coins {
+	converted := sdk.NewCoins()
+	for _, coin := range coins {
+		if coin.Denom == sdk.DefaultBondDenom {
+			coin.Denom = params.DefaultBondDenom
+		}
+		converted = converted.Add(coin)
+	}
+
+	return converted
+}
+
+// nextTxs returns the txs of the next block: at most one tx per account, so
+// that the sequences read from rpsApp are those expected by the block.
+func (w *determinismWorkload) nextTxs(r *rand.Rand, rpsApp *app.RPSApp) [][]byte {
+	// the genesis accounts are only readable once the first block is committed
+	if rpsApp.LastBlockHeight() == 0 {
+		return nil
+	}
+
+	ctx := rpsApp.NewContext(true)
+	valAddr := sdk.ValAddress(w.validator.Address).String()
+
+	var txs [][]byte
+	for _, i := range r.Perm(len(w.accounts))[:r.Intn(*flagDeterminismTxs+1)] {
+		key := w.accounts[i]
+		addr := sdk.AccAddress(key.PubKey().Address())
+		to := sdk.AccAddress(w.accounts[r.Intn(len(w.accounts))].PubKey().Address())
+		amount := sdk.NewCoin(params.DefaultBondDenom, math.NewInt(1+r.Int63n(1_000_000)))
+
+		var msg sdk.Msg
+		switch r.Intn(5) {
+		case 0:
+			msg = banktypes.NewMsgSend(addr, to, sdk.NewCoins(amount))
+		case 1:
+			msg = banktypes.NewMsgMultiSend(
+				banktypes.NewInput(addr, sdk.NewCoins(amount.Add(amount))),
+				[]banktypes.Output{banktypes.NewOutput(to, sdk.NewCoins(amount)), banktypes.NewOutput(addr, sdk.NewCoins(amount))},
+			)
+		case 2:
+			msg = stakingtypes.NewMsgDelegate(addr.String(), valAddr, amount)
+		case 3:
+			// fails unless enough was delegated, which is part of the workload
+			msg = stakingtypes.NewMsgUndelegate(addr.String(), valAddr, amount)
+		default:
+			msg = distrtypes.NewMsgWithdrawDelegatorReward(addr.String(), valAddr)
+		}
+
+		acc := rpsApp.AccountKeeper.GetAccount(ctx, addr)
+		msgs := []sdk.Msg{msg}
+		tx, err := simtestutil.GenSignedMockTx(
+			r,
+			rpsApp.TxConfig(),
+			msgs,
+			ante.DefaultParams().MinFee(msgs),
+			determinismGas,
+			determinismChainID,
+			[]uint64{acc.GetAccountNumber()},
+			[]uint64{acc.GetSequence()},
+			key,
+		)
+		require.NoError(w.t, err)
+
+		txBytes, err := rpsApp.TxConfig().TxEncoder()(tx)
+		require.NoError(w.t, err)
+
+		txs = append(txs, txBytes)
+	}
+
+	return txs
+}
@@ -36,6 +36,10 @@ func (app *RPSApp) ExportAppStateAndValidators(
 		return servertypes.ExportedApp{}, fmt.Errorf("failed to export genesis state: %w", err)
 	}
 
+	if err := app.removeLocalhostConnection(genState); err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
 	appState, err := json.MarshalIndent(genState, "", "  ")
 	if err != nil {
 		return servertypes.ExportedApp{}, err
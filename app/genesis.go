@@ -0,0 +1,77 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	crisistypes "github.com/cosmos/cosmos-sdk/x/crisis/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// SetDenomGenesis sets the rps denom in the module genesis states whose
+// defaults use the bond denom of the sdk, which the chain leaves untouched.
+// The modules missing from genesisState are skipped.
+func SetDenomGenesis(cdc codec.JSONCodec, genesisState map[string]json.RawMessage) error {
+	denom := params.DefaultBondDenom
+
+	if bz, ok := genesisState[stakingtypes.ModuleName]; ok {
+		var stakingGenState stakingtypes.GenesisState
+		if err := cdc.UnmarshalJSON(bz, &stakingGenState); err != nil {
+			return err
+		}
+
+		stakingGenState.Params.BondDenom = denom
+		genesisState[stakingtypes.ModuleName] = cdc.MustMarshalJSON(&stakingGenState)
+	}
+
+	if bz, ok := genesisState[minttypes.ModuleName]; ok {
+		var mintGenState minttypes.GenesisState
+		if err := cdc.UnmarshalJSON(bz, &mintGenState); err != nil {
+			return err
+		}
+
+		mintGenState.Params.MintDenom = denom
+		genesisState[minttypes.ModuleName] = cdc.MustMarshalJSON(&mintGenState)
+	}
+
+	if bz, ok := genesisState[crisistypes.ModuleName]; ok {
+		var crisisGenState crisistypes.GenesisState
+		if err := cdc.UnmarshalJSON(bz, &crisisGenState); err != nil {
+			return err
+		}
+
+		crisisGenState.ConstantFee.Denom = denom
+		genesisState[crisistypes.ModuleName] = cdc.MustMarshalJSON(&crisisGenState)
+	}
+
+	if bz, ok := genesisState[govtypes.ModuleName]; ok {
+		var govGenState govv1.GenesisState
+		if err := cdc.UnmarshalJSON(bz, &govGenState); err != nil {
+			return err
+		}
+
+		if govGenState.Params != nil {
+			govGenState.Params.MinDeposit = withDenom(govGenState.Params.MinDeposit)
+			govGenState.Params.ExpeditedMinDeposit = withDenom(govGenState.Params.ExpeditedMinDeposit)
+		}
+		genesisState[govtypes.ModuleName] = cdc.MustMarshalJSON(&govGenState)
+	}
+
+	return nil
+}
+
+// withDenom returns the amounts of coins in the rps denom.
+func withDenom(coins sdk.Coins) sdk.Coins {
+	converted := make(sdk.Coins, 0, len(coins))
+	for _, coin := range coins {
+		converted = converted.Add(sdk.NewCoin(params.DefaultBondDenom, coin.Amount))
+	}
+
+	return converted
+}
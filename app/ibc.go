@@ -0,0 +1,167 @@
+package app
+
+import (
+	"encoding/json"
+
+	"cosmossdk.io/core/appmodule"
+	storetypes "cosmossdk.io/store/types"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+
+	"github.com/cosmos/ibc-go/modules/capability"
+	capabilitykeeper "github.com/cosmos/ibc-go/modules/capability/keeper"
+	capabilitytypes "github.com/cosmos/ibc-go/modules/capability/types"
+	"github.com/cosmos/ibc-go/v8/modules/apps/transfer"
+	ibctransferkeeper "github.com/cosmos/ibc-go/v8/modules/apps/transfer/keeper"
+	ibctransfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	ibc "github.com/cosmos/ibc-go/v8/modules/core"
+	porttypes "github.com/cosmos/ibc-go/v8/modules/core/05-port/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+	ibckeeper "github.com/cosmos/ibc-go/v8/modules/core/keeper"
+	ibctypes "github.com/cosmos/ibc-go/v8/modules/core/types"
+	solomachine "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
+	ibctm "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+)
+
+// registerIBCModules registers the stores, keepers and modules of IBC core,
+// the ICS-20 transfer app and the capability module, which don't support
+// app wiring. The modules are ordered in app.yaml along with the others.
+func (app *RPSApp) registerIBCModules() error {
+	if err := app.RegisterStores(
+		storetypes.NewKVStoreKey(capabilitytypes.StoreKey),
+		storetypes.NewKVStoreKey(ibcexported.StoreKey),
+		storetypes.NewKVStoreKey(ibctransfertypes.StoreKey),
+		storetypes.NewMemoryStoreKey(capabilitytypes.MemStoreKey),
+	); err != nil {
+		return err
+	}
+
+	// the IBC params are updated by governance proposals, the gov module
+	// account being the authority of every module
+	authority := authtypes.NewModuleAddress(govtypes.ModuleName).String()
+
+	app.CapabilityKeeper = capabilitykeeper.NewKeeper(
+		app.appCodec,
+		app.GetKey(capabilitytypes.StoreKey),
+		app.GetMemKey(capabilitytypes.MemStoreKey),
+	)
+
+	// the capabilities are scoped before the keeper is sealed
+	app.ScopedIBCKeeper = app.CapabilityKeeper.ScopeToModule(ibcexported.ModuleName)
+	app.ScopedTransferKeeper = app.CapabilityKeeper.ScopeToModule(ibctransfertypes.ModuleName)
+	app.CapabilityKeeper.Seal()
+
+	// the chain never ran x/params, so there are no legacy params subspaces
+	// to migrate the IBC params from
+	app.IBCKeeper = ibckeeper.NewKeeper(
+		app.appCodec,
+		app.GetKey(ibcexported.StoreKey),
+		nil,
+		app.StakingKeeper,
+		app.UpgradeKeeper,
+		app.ScopedIBCKeeper,
+		authority,
+	)
+
+	app.TransferKeeper = ibctransferkeeper.NewKeeper(
+		app.appCodec,
+		app.GetKey(ibctransfertypes.StoreKey),
+		nil,
+		app.IBCKeeper.ChannelKeeper,
+		app.IBCKeeper.ChannelKeeper,
+		app.IBCKeeper.PortKeeper,
+		app.AccountKeeper,
+		app.BankKeeper,
+		app.ScopedTransferKeeper,
+		authority,
+	)
+
+	// route the packets of the transfer port to the ICS-20 app
+	ibcRouter := porttypes.NewRouter().
+		AddRoute(ibctransfertypes.ModuleName, transfer.NewIBCModule(app.TransferKeeper))
+	app.IBCKeeper.SetRouter(ibcRouter)
+
+	return app.RegisterModules(
+		capability.NewAppModule(app.appCodec, *app.CapabilityKeeper, false),
+		ibc.NewAppModule(app.IBCKeeper),
+		transfer.NewAppModule(app.TransferKeeper),
+		ibctm.NewAppModule(),
+		solomachine.NewAppModule(),
+	)
+}
+
+// RegisterIBC registers the interfaces of the IBC modules and returns their
+// basic modules, which the client adds to those of app wiring for its
+// commands and the default genesis.
+func RegisterIBC(registry codectypes.InterfaceRegistry) map[string]appmodule.AppModule {
+	modules := map[string]appmodule.AppModule{
+		capabilitytypes.ModuleName:  capability.AppModule{},
+		ibcexported.ModuleName:      ibc.AppModule{},
+		ibctransfertypes.ModuleName: transfer.AppModule{},
+		ibctm.ModuleName:            ibctm.AppModule{},
+		solomachine.ModuleName:      solomachine.AppModule{},
+	}
+
+	for name, m := range modules {
+		module.CoreAppModuleBasicAdaptor(name, m).RegisterInterfaces(registry)
+	}
+
+	return modules
+}
+
+// removeLocalhostConnection removes the sentinel localhost connection from
+// the exported IBC genesis state: its identifier doesn't pass the genesis
+// validation, and InitGenesis creates it again.
+func (app *RPSApp) removeLocalhostConnection(genState map[string]json.RawMessage) error {
+	bz, ok := genState[ibcexported.ModuleName]
+	if !ok {
+		return nil
+	}
+
+	var ibcGenState ibctypes.GenesisState
+	if err := app.appCodec.UnmarshalJSON(bz, &ibcGenState); err != nil {
+		return err
+	}
+
+	connections := ibcGenState.ConnectionGenesis.Connections[:0]
+	for _, connection := range ibcGenState.ConnectionGenesis.Connections {
+		if connection.Id != ibcexported.LocalhostConnectionID {
+			connections = append(connections, connection)
+		}
+	}
+	ibcGenState.ConnectionGenesis.Connections = connections
+
+	bz, err := app.appCodec.MarshalJSON(&ibcGenState)
+	if err != nil {
+		return err
+	}
+	genState[ibcexported.ModuleName] = bz
+
+	return nil
+}
+
+// DefaultGenesis returns the default genesis state of the modules, the IBC
+// modules included.
+func (app *RPSApp) DefaultGenesis() map[string]json.RawMessage {
+	ibcModules := module.NewManagerFromMap(RegisterIBC(app.interfaceRegistry))
+
+	genesis := app.App.DefaultGenesis()
+	for name, state := range module.NewBasicManagerFromManager(ibcModules, nil).DefaultGenesis(app.appCodec) {
+		genesis[name] = state
+	}
+
+	return genesis
+}
+
+// GetMemKey returns the MemoryStoreKey for the provided store key.
+func (app *RPSApp) GetMemKey(storeKey string) *storetypes.MemoryStoreKey {
+	key, ok := app.UnsafeFindStoreKey(storeKey).(*storetypes.MemoryStoreKey)
+	if !ok {
+		return nil
+	}
+
+	return key
+}
@@ -0,0 +1,200 @@
+package app_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	capabilitykeeper "github.com/cosmos/ibc-go/modules/capability/keeper"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	ibckeeper "github.com/cosmos/ibc-go/v8/modules/core/keeper"
+	ibctesting "github.com/cosmos/ibc-go/v8/testing"
+	ibctestingtypes "github.com/cosmos/ibc-go/v8/testing/types"
+
+	"github.com/0xlb/rps-chain/app"
+	"github.com/0xlb/rps-chain/app/ante"
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// ibcTestingApp is an RPSApp implementing the ibctesting.TestingApp interface.
+type ibcTestingApp struct {
+	*app.RPSApp
+}
+
+var _ ibctesting.TestingApp = ibcTestingApp{}
+
+func (a ibcTestingApp) GetBaseApp() *baseapp.BaseApp                      { return a.App.BaseApp }
+func (a ibcTestingApp) GetStakingKeeper() ibctestingtypes.StakingKeeper   { return a.StakingKeeper }
+func (a ibcTestingApp) GetIBCKeeper() *ibckeeper.Keeper                   { return a.IBCKeeper }
+func (a ibcTestingApp) GetScopedIBCKeeper() capabilitykeeper.ScopedKeeper { return a.ScopedIBCKeeper }
+func (a ibcTestingApp) GetTxConfig() client.TxConfig                      { return a.TxConfig() }
+
+// newIBCCoordinator returns a coordinator of two RPSApp chains, whose txs pay
+// the minimum fees of the ante handler.
+func newIBCCoordinator(t *testing.T) (*ibctesting.Coordinator, *ibctesting.TestChain, *ibctesting.TestChain) {
+	t.Helper()
+
+	// ibctesting funds its accounts, bonds its validators and sets the staking
+	// params in the bond denom of the sdk, which is therefore set to rps for
+	// the duration of the test only, a node leaving it untouched
+	bondDenom := sdk.DefaultBondDenom
+	sdk.DefaultBondDenom = params.DefaultBondDenom
+	t.Cleanup(func() { sdk.DefaultBondDenom = bondDenom })
+
+	ibctesting.DefaultTestingAppInit = func() (ibctesting.TestingApp, map[string]json.RawMessage) {
+		rpsApp, err := app.NewRPSApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, simtestutil.NewAppOptionsWithFlagHome(t.TempDir()))
+		require.NoError(t, err)
+
+		return ibcTestingApp{rpsApp}, rpsApp.DefaultGenesis()
+	}
+
+	coord := ibctesting.NewCoordinator(t, 2)
+	chainA := coord.GetChain(ibctesting.GetChainID(1))
+	chainB := coord.GetChain(ibctesting.GetChainID(2))
+
+	for _, chain := range []*ibctesting.TestChain{chainA, chainB} {
+		chain.SendMsgsOverride = sendMsgsWithFees(t, chain)
+	}
+
+	return coord, chainA, chainB
+}
+
+// sendMsgsWithFees delivers the msgs as ibctesting.TestChain.SendMsgs does,
+// but in a tx paying the minimum fee of the ante handler.
+func sendMsgsWithFees(t *testing.T, chain *ibctesting.TestChain) func(...sdk.Msg) (*abci.ExecTxResult, error) {
+	return func(msgs ...sdk.Msg) (*abci.ExecTxResult, error) {
+		chain.Coordinator.UpdateTimeForChain(chain)
+
+		tx, err := simtestutil.GenSignedMockTx(
+			rand.New(rand.NewSource(chain.CurrentHeader.Height)),
+			chain.TxConfig,
+			msgs,
+			ante.DefaultParams().MinFee(msgs),
+			simtestutil.DefaultGenTxGas,
+			chain.ChainID,
+			[]uint64{chain.SenderAccount.GetAccountNumber()},
+			[]uint64{chain.SenderAccount.GetSequence()},
+			chain.SenderPrivKey,
+		)
+		require.NoError(t, err)
+
+		// the sequence is incremented whether the tx succeeds or not
+		require.NoError(t, chain.SenderAccount.SetSequence(chain.SenderAccount.GetSequence()+1))
+
+		txBytes, err := chain.TxConfig.TxEncoder()(tx)
+		require.NoError(t, err)
+
+		res, err := chain.App.FinalizeBlock(&abci.RequestFinalizeBlock{
+			Height:             chain.App.LastBlockHeight() + 1,
+			Time:               chain.CurrentHeader.GetTime(),
+			NextValidatorsHash: chain.NextVals.Hash(),
+			Txs:                [][]byte{txBytes},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		commitBlock(t, chain, res)
+
+		require.Len(t, res.TxResults, 1)
+		txResult := res.TxResults[0]
+		if txResult.Code != 0 {
+			return txResult, fmt.Errorf("%s/%d: %q", txResult.Codespace, txResult.Code, txResult.Log)
+		}
+
+		chain.Coordinator.IncrementTime()
+
+		return txResult, nil
+	}
+}
+
+// commitBlock commits the finalized block and moves the chain headers to
+// the next block, as the unexported ibctesting.TestChain.commitBlock does.
+func commitBlock(t *testing.T, chain *ibctesting.TestChain, res *abci.ResponseFinalizeBlock) {
+	_, err := chain.App.Commit()
+	require.NoError(t, err)
+
+	chain.LastHeader = chain.CurrentTMClientHeader()
+
+	chain.Vals = chain.NextVals
+	chain.NextVals = ibctesting.ApplyValSetChanges(t, chain.Vals, res.ValidatorUpdates)
+
+	chain.CurrentHeader = cmtproto.Header{
+		ChainID:            chain.ChainID,
+		Height:             chain.App.LastBlockHeight() + 1,
+		AppHash:            chain.App.LastCommitID().Hash,
+		Time:               chain.CurrentHeader.Time,
+		ValidatorsHash:     chain.Vals.Hash(),
+		NextValidatorsHash: chain.NextVals.Hash(),
+		ProposerAddress:    chain.CurrentHeader.ProposerAddress,
+	}
+}
+
+// transfer sends coin from the sender of the chain of the endpoint to the
+// sender of its counterparty chain and relays the packet and its ack.
+func transfer(t *testing.T, path *ibctesting.Path, from, to *ibctesting.Endpoint, coin sdk.Coin) {
+	t.Helper()
+
+	msg := transfertypes.NewMsgTransfer(
+		from.ChannelConfig.PortID,
+		from.ChannelID,
+		coin,
+		from.Chain.SenderAccount.GetAddress().String(),
+		to.Chain.SenderAccount.GetAddress().String(),
+		clienttypes.NewHeight(1, 1000),
+		0,
+		"",
+	)
+
+	res, err := from.Chain.SendMsgs(msg)
+	require.NoError(t, err)
+
+	packet, err := ibctesting.ParsePacketFromEvents(res.Events)
+	require.NoError(t, err)
+
+	require.NoError(t, path.RelayPacket(packet))
+}
+
+func TestIBCTransferRoundTrip(t *testing.T) {
+	coord, chainA, chainB := newIBCCoordinator(t)
+
+	path := ibctesting.NewTransferPath(chainA, chainB)
+	coord.Setup(path)
+
+	amount := math.NewInt(1_000_000)
+	escrow := transfertypes.GetEscrowAddress(path.EndpointA.ChannelConfig.PortID, path.EndpointA.ChannelID)
+	voucherDenom := transfertypes.ParseDenomTrace(
+		transfertypes.GetPrefixedDenom(path.EndpointB.ChannelConfig.PortID, path.EndpointB.ChannelID, params.DefaultBondDenom),
+	).IBCDenom()
+
+	appA := chainA.App.(ibcTestingApp)
+	appB := chainB.App.(ibcTestingApp)
+
+	// rps are escrowed on chain A and vouchers minted on chain B
+	transfer(t, path, path.EndpointA, path.EndpointB, sdk.NewCoin(params.DefaultBondDenom, amount))
+
+	require.Equal(t, amount, appA.BankKeeper.GetBalance(chainA.GetContext(), escrow, params.DefaultBondDenom).Amount)
+	require.Equal(t, amount, appB.BankKeeper.GetBalance(chainB.GetContext(), chainB.SenderAccount.GetAddress(), voucherDenom).Amount)
+
+	// the vouchers are burnt on chain B and the rps released on chain A
+	transfer(t, path, path.EndpointB, path.EndpointA, sdk.NewCoin(voucherDenom, amount))
+
+	require.True(t, appA.BankKeeper.GetBalance(chainA.GetContext(), escrow, params.DefaultBondDenom).IsZero())
+	require.True(t, appB.BankKeeper.GetBalance(chainB.GetContext(), chainB.SenderAccount.GetAddress(), voucherDenom).IsZero())
+	require.True(t, appB.BankKeeper.GetSupply(chainB.GetContext(), voucherDenom).IsZero())
+}
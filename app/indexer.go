@@ -0,0 +1,61 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cast"
+
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+
+	"github.com/0xlb/rps-chain/app/indexer"
+)
+
+// registerIndexer opens the indexer database in the node data directory and
+// sets the indexer as the ABCI listener of the app, listening to the indexed
+// stores. It replaces the listener of the streaming plugins, which therefore
+// can't be enabled along with the indexer.
+func (app *RPSApp) registerIndexer(appOpts servertypes.AppOptions) error {
+	pluginKey := fmt.Sprintf("%s.%s.%s", baseapp.StreamingTomlKey, baseapp.StreamingABCITomlKey, baseapp.StreamingABCIPluginTomlKey)
+	if plugin := cast.ToString(appOpts.Get(pluginKey)); plugin != "" {
+		return fmt.Errorf("%s cannot be enabled along with the %q streaming plugin", flagIndexerEnabled, plugin)
+	}
+
+	storeKeys := make([]storetypes.StoreKey, 0, len(indexer.StoreKeys))
+	for _, name := range indexer.StoreKeys {
+		key := app.GetKey(name)
+		if key == nil {
+			return fmt.Errorf("indexed store %s is not registered", name)
+		}
+		storeKeys = append(storeKeys, key)
+	}
+
+	dbPath := filepath.Join(cast.ToString(appOpts.Get(flags.FlagHome)), "data", indexer.DBName)
+	idx, err := indexer.Open(dbPath, app.appCodec, app.Logger().With("module", "indexer"))
+	if err != nil {
+		return err
+	}
+
+	app.CommitMultiStore().AddListeners(storeKeys)
+	app.SetStreamingManager(storetypes.StreamingManager{
+		ABCIListeners: []storetypes.ABCIListener{idx},
+	})
+	app.indexer = idx
+
+	return nil
+}
+
+// Close closes the app and the indexer database.
+func (app *RPSApp) Close() error {
+	err := app.App.Close()
+	if app.indexer != nil {
+		err = errors.Join(err, app.indexer.Close())
+	}
+
+	return err
+}
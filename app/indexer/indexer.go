@@ -0,0 +1,356 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/log"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	_ "modernc.org/sqlite" // register the sqlite database/sql driver
+)
+
+// DBName is the file name of the indexer database in the node data directory.
+const DBName = "indexer.db"
+
+// blockEventsTxIndex is the tx index of the block events in the events table.
+const blockEventsTxIndex = -1
+
+//go:embed schema.sql
+var schema string
+
+// StoreKeys are the names of the stores whose changes are indexed.
+var StoreKeys = []string{banktypes.StoreKey, stakingtypes.StoreKey}
+
+var _ storetypes.ABCIListener = (*Indexer)(nil)
+
+// balancesKeyCodec is the key codec of the x/bank balances, following the
+// balances prefix.
+var balancesKeyCodec = collections.PairKeyCodec(sdk.AccAddressKey, collections.StringKey)
+
+// Indexer is an ABCI listener writing the finalized blocks, their txs and
+// events, and the bank balances and staking delegations they change into a
+// SQLite database. The schema is documented in schema.sql.
+//
+// Blocks at or below the database cursor are skipped, so that the blocks
+// replayed on restart aren't indexed twice. The indexer stops writing at the
+// first block it can't index or that doesn't follow the cursor, e.g. when the
+// node crashed between a commit and its indexing: the database then stays at
+// its cursor and must be rebuilt, see the README. Since baseapp only logs the
+// listener errors, every later block logs the error stopping the indexer.
+type Indexer struct {
+	db     *sql.DB
+	cdc    codec.BinaryCodec
+	logger log.Logger
+
+	// height is the height of the last indexed block.
+	height int64
+	// pending is the block finalized but not yet committed.
+	pending *finalizedBlock
+	// stopped is the error which stopped the indexer, nil while it runs.
+	stopped error
+}
+
+type finalizedBlock struct {
+	req abci.RequestFinalizeBlock
+	res abci.ResponseFinalizeBlock
+}
+
+type eventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Open opens or creates the indexer database at path and reads its cursor.
+func Open(path string, cdc codec.BinaryCodec, logger log.Logger) (*Indexer, error) {
+	// WAL lets external readers query the database while blocks are written
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to create the indexer schema: %w", err), db.Close())
+	}
+
+	var height int64
+	if err := db.QueryRow("SELECT height FROM cursor WHERE id = 0").Scan(&height); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Join(fmt.Errorf("failed to read the indexer cursor: %w", err), db.Close())
+	}
+
+	logger.Info("opened indexer database", "path", path, "height", height)
+
+	return &Indexer{
+		db:     db,
+		cdc:    cdc,
+		logger: logger,
+		height: height,
+	}, nil
+}
+
+// Height returns the height of the last indexed block, 0 if none.
+func (i *Indexer) Height() int64 {
+	return i.height
+}
+
+// Stopped returns the error which stopped the indexer, nil while it runs.
+func (i *Indexer) Stopped() error {
+	return i.stopped
+}
+
+// stop stops the indexer at its cursor because of err.
+func (i *Indexer) stop(err error) error {
+	i.stopped = fmt.Errorf("indexer stopped at height %d, the database must be rebuilt: %w", i.height, err)
+	i.logger.Error("indexer stopped", "height", i.height, "err", err)
+
+	return i.stopped
+}
+
+// Close closes the indexer database.
+func (i *Indexer) Close() error {
+	return i.db.Close()
+}
+
+// ListenFinalizeBlock implements storetypes.ABCIListener. The block is only
+// written once committed.
+func (i *Indexer) ListenFinalizeBlock(_ context.Context, req abci.RequestFinalizeBlock, res abci.ResponseFinalizeBlock) error {
+	i.pending = nil
+
+	if i.stopped != nil {
+		return i.stopped
+	}
+
+	if req.Height <= i.height {
+		return nil
+	}
+
+	// an empty database starts at any height, a non empty one can't skip blocks
+	if i.height > 0 && req.Height > i.height+1 {
+		return i.stop(fmt.Errorf("missing blocks %d to %d", i.height+1, req.Height-1))
+	}
+
+	i.pending = &finalizedBlock{req: req, res: res}
+
+	return nil
+}
+
+// ListenCommit implements storetypes.ABCIListener. It writes the pending
+// block, its state changes and the cursor in a single SQL transaction. The
+// indexer stops if the transaction fails.
+func (i *Indexer) ListenCommit(ctx context.Context, _ abci.ResponseCommit, changeSet []*storetypes.StoreKVPair) error {
+	if i.stopped != nil {
+		return i.stopped
+	}
+
+	block := i.pending
+	if block == nil {
+		return nil
+	}
+	i.pending = nil
+
+	if err := i.writeCommittedBlock(ctx, block, changeSet); err != nil {
+		return i.stop(err)
+	}
+	i.height = block.req.Height
+
+	return nil
+}
+
+func (i *Indexer) writeCommittedBlock(ctx context.Context, block *finalizedBlock, changeSet []*storetypes.StoreKVPair) error {
+	height := block.req.Height
+
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	if err := writeBlock(tx, block); err != nil {
+		return fmt.Errorf("failed to index block %d: %w", height, err)
+	}
+
+	for _, pair := range changeSet {
+		if err := i.writeChange(tx, height, pair); err != nil {
+			return fmt.Errorf("failed to index %s store change at height %d: %w", pair.StoreKey, height, err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT OR REPLACE INTO cursor (id, height) VALUES (0, ?)", height); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func writeBlock(tx *sql.Tx, block *finalizedBlock) error {
+	req, res := block.req, block.res
+
+	if _, err := tx.Exec(
+		"INSERT INTO blocks (height, hash, time, proposer, app_hash, num_txs) VALUES (?, ?, ?, ?, ?, ?)",
+		req.Height,
+		fmt.Sprintf("%X", req.Hash),
+		req.Time.UTC().Format(time.RFC3339Nano),
+		fmt.Sprintf("%X", req.ProposerAddress),
+		fmt.Sprintf("%X", res.AppHash),
+		len(req.Txs),
+	); err != nil {
+		return err
+	}
+
+	if err := writeEvents(tx, req.Height, blockEventsTxIndex, res.Events); err != nil {
+		return err
+	}
+
+	for txIndex, txBytes := range req.Txs {
+		// the tx results match the txs of the request, but guard against a
+		// malformed response rather than panicking in the commit
+		if txIndex >= len(res.TxResults) {
+			return fmt.Errorf("missing result of tx %d", txIndex)
+		}
+		result := res.TxResults[txIndex]
+
+		if _, err := tx.Exec(
+			"INSERT INTO txs (height, tx_index, hash, code, codespace, log, gas_wanted, gas_used) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			req.Height,
+			txIndex,
+			fmt.Sprintf("%X", cmttypes.Tx(txBytes).Hash()),
+			result.Code,
+			result.Codespace,
+			result.Log,
+			result.GasWanted,
+			result.GasUsed,
+		); err != nil {
+			return err
+		}
+
+		if err := writeEvents(tx, req.Height, txIndex, result.Events); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeEvents(tx *sql.Tx, height int64, txIndex int, events []abci.Event) error {
+	for eventIndex, event := range events {
+		attributes := make([]eventAttribute, len(event.Attributes))
+		for j, attr := range event.Attributes {
+			attributes[j] = eventAttribute{Key: attr.Key, Value: attr.Value}
+		}
+
+		bz, err := json.Marshal(attributes)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(
+			"INSERT INTO events (height, tx_index, event_index, type, attributes) VALUES (?, ?, ?, ?, ?)",
+			height, txIndex, eventIndex, event.Type, string(bz),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeChange applies a store change to the balances and delegations tables.
+// The changes of the other stores and prefixes are ignored.
+func (i *Indexer) writeChange(tx *sql.Tx, height int64, pair *storetypes.StoreKVPair) error {
+	switch pair.StoreKey {
+	case banktypes.StoreKey:
+		if bytes.HasPrefix(pair.Key, banktypes.BalancesPrefix) {
+			return writeBalance(tx, height, pair)
+		}
+	case stakingtypes.StoreKey:
+		if bytes.HasPrefix(pair.Key, stakingtypes.DelegationKey) {
+			return i.writeDelegation(tx, height, pair)
+		}
+	}
+
+	return nil
+}
+
+func writeBalance(tx *sql.Tx, height int64, pair *storetypes.StoreKVPair) error {
+	_, key, err := balancesKeyCodec.Decode(pair.Key[len(banktypes.BalancesPrefix):])
+	if err != nil {
+		return err
+	}
+	address, denom := key.K1().String(), key.K2()
+
+	if pair.Delete {
+		_, err := tx.Exec("DELETE FROM balances WHERE address = ? AND denom = ?", address, denom)
+		return err
+	}
+
+	amount, err := banktypes.BalanceValueCodec.Decode(pair.Value)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT OR REPLACE INTO balances (address, denom, amount, height) VALUES (?, ?, ?, ?)",
+		address, denom, amount.String(), height,
+	)
+	return err
+}
+
+func (i *Indexer) writeDelegation(tx *sql.Tx, height int64, pair *storetypes.StoreKVPair) error {
+	delAddr, valAddr, err := parseDelegationKey(pair.Key[len(stakingtypes.DelegationKey):])
+	if err != nil {
+		return err
+	}
+	delegator, validator := delAddr.String(), valAddr.String()
+
+	if pair.Delete {
+		_, err := tx.Exec("DELETE FROM delegations WHERE delegator = ? AND validator = ?", delegator, validator)
+		return err
+	}
+
+	var delegation stakingtypes.Delegation
+	if err := i.cdc.Unmarshal(pair.Value, &delegation); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT OR REPLACE INTO delegations (delegator, validator, shares, height) VALUES (?, ?, ?, ?)",
+		delegator, validator, delegation.Shares.String(), height,
+	)
+	return err
+}
+
+// parseDelegationKey parses a staking delegation key, without its prefix,
+// made of the length prefixed delegator and validator addresses.
+func parseDelegationKey(key []byte) (sdk.AccAddress, sdk.ValAddress, error) {
+	n, delAddr, err := sdk.AccAddressKey.DecodeNonTerminal(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, valAddr, err := sdk.ValAddressKey.DecodeNonTerminal(key[n:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n+m != len(key) {
+		return nil, nil, fmt.Errorf("invalid delegation key %X", key)
+	}
+
+	return delAddr, valAddr, nil
+}
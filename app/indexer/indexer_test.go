@@ -0,0 +1,231 @@
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	storetypes "cosmossdk.io/store/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+)
+
+var (
+	delAddr = sdk.AccAddress("delegator___________")
+	valAddr = sdk.ValAddress("validator___________")
+
+	cdc = codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+)
+
+func openTestIndexer(t *testing.T, path string) *Indexer {
+	t.Helper()
+
+	i, err := Open(path, cdc, log.NewNopLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = i.Close() })
+
+	return i
+}
+
+// indexBlock finalizes and commits a block with a single tx and changeSet.
+func indexBlock(i *Indexer, height int64, changeSet ...*storetypes.StoreKVPair) error {
+	req := abci.RequestFinalizeBlock{
+		Height: height,
+		Hash:   []byte{byte(height)},
+		Time:   time.Unix(height, 0),
+		Txs:    [][]byte{{byte(height)}},
+	}
+	res := abci.ResponseFinalizeBlock{
+		Events:    []abci.Event{{Type: "block", Attributes: []abci.EventAttribute{{Key: "k", Value: "v"}}}},
+		TxResults: []*abci.ExecTxResult{{GasUsed: height}},
+	}
+
+	if err := i.ListenFinalizeBlock(context.Background(), req, res); err != nil {
+		return err
+	}
+
+	return i.ListenCommit(context.Background(), abci.ResponseCommit{}, changeSet)
+}
+
+func count(t *testing.T, i *Indexer, table string) int {
+	t.Helper()
+
+	var n int
+	require.NoError(t, i.db.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&n))
+
+	return n
+}
+
+func balancePair(t *testing.T, amount int64, del bool) *storetypes.StoreKVPair {
+	t.Helper()
+
+	key, err := collections.EncodeKeyWithPrefix(banktypes.BalancesPrefix, balancesKeyCodec, collections.Join(delAddr, "urps"))
+	require.NoError(t, err)
+
+	pair := &storetypes.StoreKVPair{StoreKey: banktypes.StoreKey, Key: key, Delete: del}
+	if !del {
+		pair.Value, err = banktypes.BalanceValueCodec.Encode(math.NewInt(amount))
+		require.NoError(t, err)
+	}
+
+	return pair
+}
+
+func delegationPair(t *testing.T, shares int64, del bool) *storetypes.StoreKVPair {
+	t.Helper()
+
+	pair := &storetypes.StoreKVPair{StoreKey: stakingtypes.StoreKey, Key: stakingtypes.GetDelegationKey(delAddr, valAddr), Delete: del}
+	if !del {
+		delegation := stakingtypes.NewDelegation(delAddr.String(), valAddr.String(), math.LegacyNewDec(shares))
+		pair.Value = cdc.MustMarshal(&delegation)
+	}
+
+	return pair
+}
+
+func TestIndexerCursor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	i := openTestIndexer(t, path)
+	require.Zero(t, i.Height())
+
+	// an empty database starts at any height
+	for height := int64(3); height <= 5; height++ {
+		require.NoError(t, indexBlock(i, height))
+	}
+	require.Equal(t, int64(5), i.Height())
+	require.Equal(t, 3, count(t, i, "blocks"))
+	require.Equal(t, 3, count(t, i, "txs"))
+	require.Equal(t, 3, count(t, i, "events"))
+	require.NoError(t, i.Close())
+
+	// the blocks replayed on restart are skipped
+	i = openTestIndexer(t, path)
+	require.Equal(t, int64(5), i.Height())
+	require.NoError(t, indexBlock(i, 4))
+	require.NoError(t, indexBlock(i, 5))
+	require.Equal(t, 3, count(t, i, "blocks"))
+
+	require.NoError(t, indexBlock(i, 6))
+	require.Equal(t, int64(6), i.Height())
+	require.Equal(t, 4, count(t, i, "blocks"))
+}
+
+func TestIndexerStopsOnGap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DBName)
+	i := openTestIndexer(t, path)
+
+	require.NoError(t, indexBlock(i, 1))
+	require.NoError(t, i.Stopped())
+
+	err := indexBlock(i, 3)
+	require.ErrorContains(t, err, "indexer stopped at height 1, the database must be rebuilt: missing blocks 2 to 2")
+	require.Equal(t, err, i.Stopped())
+
+	// the following blocks aren't written and the cursor stays at the gap
+	require.Equal(t, i.Stopped(), indexBlock(i, 4))
+	require.Equal(t, int64(1), i.Height())
+	require.Equal(t, 1, count(t, i, "blocks"))
+	require.NoError(t, i.Close())
+
+	// the gap is detected again after a restart
+	i = openTestIndexer(t, path)
+	require.Equal(t, int64(1), i.Height())
+	require.ErrorContains(t, indexBlock(i, 5), "missing blocks 2 to 4")
+}
+
+func TestIndexerStopsOnWriteFailure(t *testing.T) {
+	i := openTestIndexer(t, filepath.Join(t.TempDir(), DBName))
+
+	require.NoError(t, indexBlock(i, 1))
+
+	invalid := &storetypes.StoreKVPair{StoreKey: banktypes.StoreKey, Key: banktypes.BalancesPrefix, Value: []byte("1")}
+	require.ErrorContains(t, indexBlock(i, 2, invalid), "failed to index bank store change at height 2")
+	require.Error(t, i.Stopped())
+
+	// the failed block was rolled back and no block is written after it
+	require.Error(t, indexBlock(i, 3))
+	require.Equal(t, int64(1), i.Height())
+	require.Equal(t, 1, count(t, i, "blocks"))
+
+	var height int64
+	require.NoError(t, i.db.QueryRow("SELECT height FROM cursor WHERE id = 0").Scan(&height))
+	require.Equal(t, int64(1), height)
+}
+
+func TestIndexerBalances(t *testing.T) {
+	i := openTestIndexer(t, filepath.Join(t.TempDir(), DBName))
+
+	require.NoError(t, indexBlock(i, 1, balancePair(t, 100, false)))
+
+	var amount string
+	var height int64
+	require.NoError(t, i.db.QueryRow("SELECT amount, height FROM balances WHERE address = ? AND denom = ?", delAddr.String(), "urps").Scan(&amount, &height))
+	require.Equal(t, "100", amount)
+	require.Equal(t, int64(1), height)
+
+	require.NoError(t, indexBlock(i, 2, balancePair(t, 40, false)))
+	require.NoError(t, i.db.QueryRow("SELECT amount, height FROM balances WHERE address = ? AND denom = ?", delAddr.String(), "urps").Scan(&amount, &height))
+	require.Equal(t, "40", amount)
+	require.Equal(t, int64(2), height)
+
+	require.NoError(t, indexBlock(i, 3, balancePair(t, 0, true)))
+	require.Zero(t, count(t, i, "balances"))
+}
+
+func TestIndexerDelegations(t *testing.T) {
+	i := openTestIndexer(t, filepath.Join(t.TempDir(), DBName))
+
+	// changes of the other stores and prefixes are ignored
+	other := &storetypes.StoreKVPair{StoreKey: stakingtypes.StoreKey, Key: stakingtypes.GetValidatorKey(valAddr), Value: []byte("validator")}
+	require.NoError(t, indexBlock(i, 1, delegationPair(t, 10, false), other))
+
+	var delegator, validator, shares string
+	require.NoError(t, i.db.QueryRow("SELECT delegator, validator, shares FROM delegations").Scan(&delegator, &validator, &shares))
+	require.Equal(t, delAddr.String(), delegator)
+	require.Equal(t, valAddr.String(), validator)
+	require.Equal(t, math.LegacyNewDec(10).String(), shares)
+
+	require.NoError(t, indexBlock(i, 2, delegationPair(t, 0, true)))
+	require.Zero(t, count(t, i, "delegations"))
+}
+
+func TestParseDelegationKey(t *testing.T) {
+	key := stakingtypes.GetDelegationKey(delAddr, valAddr)[len(stakingtypes.DelegationKey):]
+
+	testCases := []struct {
+		name   string
+		key    []byte
+		expErr bool
+	}{
+		{"valid", key, false},
+		{"empty", nil, true},
+		{"truncated delegator", key[:5], true},
+		{"truncated validator", key[:len(key)-1], true},
+		{"trailing bytes", append(append([]byte{}, key...), 0), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			del, val, err := parseDelegationKey(tc.key)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, delAddr, del)
+			require.Equal(t, valAddr, val)
+		})
+	}
+}
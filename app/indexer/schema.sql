@@ -0,0 +1,70 @@
+-- Schema of the rpsd indexer database.
+--
+-- Every finalized block is written in a single SQL transaction together with
+-- the cursor, so the database always holds whole blocks and the cursor is the
+-- last height they go up to. Hashes and app hashes are upper case hex strings,
+-- addresses are bech32 strings and amounts are decimal strings.
+
+-- cursor holds a single row: the height of the last indexed block.
+CREATE TABLE IF NOT EXISTS cursor (
+    id     INTEGER PRIMARY KEY CHECK (id = 0),
+    height INTEGER NOT NULL
+);
+
+-- blocks holds one row per indexed block.
+CREATE TABLE IF NOT EXISTS blocks (
+    height   INTEGER PRIMARY KEY,
+    hash     TEXT    NOT NULL,
+    time     TEXT    NOT NULL, -- RFC 3339 with nanoseconds, UTC
+    proposer TEXT    NOT NULL, -- consensus address, hex
+    app_hash TEXT    NOT NULL, -- app hash resulting from the block
+    num_txs  INTEGER NOT NULL
+);
+
+-- txs holds one row per tx of the indexed blocks, failed txs included.
+CREATE TABLE IF NOT EXISTS txs (
+    height     INTEGER NOT NULL REFERENCES blocks (height),
+    tx_index   INTEGER NOT NULL,
+    hash       TEXT    NOT NULL,
+    code       INTEGER NOT NULL, -- 0 on success
+    codespace  TEXT    NOT NULL,
+    log        TEXT    NOT NULL,
+    gas_wanted INTEGER NOT NULL,
+    gas_used   INTEGER NOT NULL,
+    PRIMARY KEY (height, tx_index)
+);
+
+CREATE INDEX IF NOT EXISTS txs_hash ON txs (hash);
+
+-- events holds the events emitted by the indexed blocks. Block events, which
+-- are emitted by the begin and end blockers, have a tx_index of -1.
+CREATE TABLE IF NOT EXISTS events (
+    height      INTEGER NOT NULL REFERENCES blocks (height),
+    tx_index    INTEGER NOT NULL,
+    event_index INTEGER NOT NULL,
+    type        TEXT    NOT NULL,
+    attributes  TEXT    NOT NULL, -- JSON array of {"key", "value"} objects
+    PRIMARY KEY (height, tx_index, event_index)
+);
+
+CREATE INDEX IF NOT EXISTS events_type ON events (type);
+
+-- balances holds the latest bank balances changed since the indexing
+-- started. Emptied balances are deleted.
+CREATE TABLE IF NOT EXISTS balances (
+    address TEXT    NOT NULL,
+    denom   TEXT    NOT NULL,
+    amount  TEXT    NOT NULL,
+    height  INTEGER NOT NULL, -- height of the last change
+    PRIMARY KEY (address, denom)
+);
+
+-- delegations holds the latest staking delegations changed since the
+-- indexing started. Removed delegations are deleted.
+CREATE TABLE IF NOT EXISTS delegations (
+    delegator TEXT    NOT NULL,
+    validator TEXT    NOT NULL,
+    shares    TEXT    NOT NULL,
+    height    INTEGER NOT NULL, -- height of the last change
+    PRIMARY KEY (delegator, validator)
+);
@@ -0,0 +1,37 @@
+package app
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// InvariantResult is the result of an invariant of the registry.
+type InvariantResult struct {
+	// Route is the module/route name of the invariant.
+	Route string
+	// Broken is true if the invariant doesn't hold.
+	Broken bool
+	// Message describes the invariant and, if broken, the offending state.
+	Message string
+}
+
+// CheckInvariants runs every invariant registered by the modules against the
+// state of ctx, leaving it untouched, and returns their results in the
+// registration order. Unlike x/crisis, it doesn't stop at the first broken
+// invariant.
+func (app *RPSApp) CheckInvariants(ctx sdk.Context) []InvariantResult {
+	routes := app.CrisisKeeper.Routes()
+
+	results := make([]InvariantResult, 0, len(routes))
+	for _, route := range routes {
+		cacheCtx, _ := ctx.CacheContext()
+		msg, broken := route.Invar(cacheCtx)
+
+		results = append(results, InvariantResult{
+			Route:   route.FullRoute(),
+			Broken:  broken,
+			Message: msg,
+		})
+	}
+
+	return results
+}
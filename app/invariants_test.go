@@ -0,0 +1,56 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/stretchr/testify/require"
+
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	n := newDeterminismNode(t, 1)
+	w := newDeterminismWorkload(t, n.app)
+
+	_, err := n.app.InitChain(&abci.RequestInitChain{
+		Time:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ChainId:         determinismChainID,
+		ConsensusParams: simtestutil.DefaultConsensusParams,
+		AppStateBytes:   w.appState,
+		InitialHeight:   1,
+	})
+	require.NoError(t, err)
+
+	_, err = n.finalizeAndCommit(&abci.RequestFinalizeBlock{Height: 1, Time: time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC)})
+	require.NoError(t, err)
+
+	ctx := n.app.NewUncachedContext(false, cmtproto.Header{Height: n.app.LastBlockHeight()})
+
+	results := n.app.CheckInvariants(ctx)
+	require.NotEmpty(t, results)
+	for _, res := range results {
+		require.False(t, res.Broken, "%s: %s", res.Route, res.Message)
+	}
+
+	// coins sent to the bonded pool outside of x/staking aren't backed by
+	// bonded tokens
+	from := sdk.AccAddress(w.accounts[0].PubKey().Address())
+	coins := sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, 1000))
+	require.NoError(t, n.app.BankKeeper.SendCoinsFromAccountToModule(ctx, from, stakingtypes.BondedPoolName, coins))
+
+	var broken []string
+	for _, res := range n.app.CheckInvariants(ctx) {
+		if res.Broken {
+			broken = append(broken, res.Route)
+			require.Contains(t, res.Message, "bonded")
+		}
+	}
+	require.Equal(t, []string{"staking/module-accounts"}, broken)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"math"
+
+	"github.com/spf13/cast"
+
+	"github.com/cosmos/cosmos-sdk/server"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// TimeSensitiveMsgTypes are the type URLs of the messages which must be
+// included before a deadline, such as game reveals. Txs containing any of
+// them are ordered before every other tx of the mempool.
+var TimeSensitiveMsgTypes = []string{}
+
+// gasPriceScale is the scale of TxPriority.GasPrice, in bond denom units.
+const gasPriceScale = 1_000_000
+
+// TxPriority is the priority of a tx in the mempool. Time sensitive txs come
+// first, then txs are ordered by gas price.
+type TxPriority struct {
+	TimeSensitive bool
+	// GasPrice is the bond denom fee paid per gas unit, scaled by 10^6.
+	GasPrice int64
+}
+
+// NewTxPriority returns the mempool.TxPriority ordering txs by TxPriority,
+// boosting the txs with messages of the given time sensitive types.
+func NewTxPriority(timeSensitiveMsgTypes []string) mempool.TxPriority[TxPriority] {
+	timeSensitive := make(map[string]bool, len(timeSensitiveMsgTypes))
+	for _, typeURL := range timeSensitiveMsgTypes {
+		timeSensitive[typeURL] = true
+	}
+
+	return mempool.TxPriority[TxPriority]{
+		GetTxPriority: func(_ context.Context, tx sdk.Tx) TxPriority {
+			var priority TxPriority
+			for _, msg := range tx.GetMsgs() {
+				if timeSensitive[sdk.MsgTypeURL(msg)] {
+					priority.TimeSensitive = true
+					break
+				}
+			}
+
+			feeTx, ok := tx.(sdk.FeeTx)
+			if !ok || feeTx.GetGas() == 0 {
+				return priority
+			}
+
+			gasPrice := feeTx.GetFee().AmountOf(params.DefaultBondDenom).MulRaw(gasPriceScale).QuoRaw(int64(feeTx.GetGas()))
+			if gasPrice.IsInt64() {
+				priority.GasPrice = gasPrice.Int64()
+			} else {
+				priority.GasPrice = math.MaxInt64
+			}
+
+			return priority
+		},
+		Compare: func(a, b TxPriority) int {
+			switch {
+			case a.TimeSensitive != b.TimeSensitive:
+				if a.TimeSensitive {
+					return 1
+				}
+				return -1
+			case a.GasPrice > b.GasPrice:
+				return 1
+			case a.GasPrice < b.GasPrice:
+				return -1
+			default:
+				return 0
+			}
+		},
+		MinValue: TxPriority{GasPrice: math.MinInt64},
+	}
+}
+
+// NewMempool returns the app mempool, ordering txs by TxPriority and sender
+// nonce. Its capacity is read from the mempool.max-txs option of app.toml.
+// A negative capacity disables the app mempool as in the SDK: a NoOpMempool
+// is returned, with which the proposal handlers use the CometBFT mempool txs.
+func NewMempool(appOpts servertypes.AppOptions) mempool.Mempool {
+	maxTxs := cast.ToInt(appOpts.Get(server.FlagMempoolMaxTxs))
+	if maxTxs < 0 {
+		return mempool.NoOpMempool{}
+	}
+
+	return mempool.NewPriorityMempool(mempool.PriorityNonceMempoolConfig[TxPriority]{
+		TxPriority: NewTxPriority(TimeSensitiveMsgTypes),
+		MaxTx:      maxTxs,
+	})
+}
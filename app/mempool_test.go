@@ -0,0 +1,223 @@
+package app_test
+
+import (
+	"math"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/stretchr/testify/require"
+	protov2 "google.golang.org/protobuf/proto"
+
+	"cosmossdk.io/log"
+	sdkmath "cosmossdk.io/math"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectestutil "github.com/cosmos/cosmos-sdk/codec/testutil"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/server"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/mempool"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/0xlb/rps-chain/app"
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// timeSensitiveMsg stands for a time sensitive message such as a game
+// reveal, which the chain doesn't have yet.
+var timeSensitiveMsg = sdk.MsgTypeURL(&banktypes.MsgMultiSend{})
+
+func newMempoolTxConfig() client.TxConfig {
+	interfaceRegistry := codectestutil.CodecOptions{
+		AccAddressPrefix: params.Bech32PrefixAccAddr,
+		ValAddressPrefix: params.Bech32PrefixValAddr,
+	}.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(interfaceRegistry)
+	banktypes.RegisterInterfaces(interfaceRegistry)
+
+	return authtx.NewTxConfig(codec.NewProtoCodec(interfaceRegistry), authtx.DefaultSignModes)
+}
+
+// mempoolTx returns a tx of the signer named sender, at sequence 0, paying
+// fee for gas.
+func mempoolTx(t *testing.T, txConfig client.TxConfig, sender string, timeSensitive bool, fee sdk.Coins, gas uint64) sdk.Tx {
+	t.Helper()
+
+	pubKey := secp256k1.GenPrivKeyFromSecret([]byte(sender)).PubKey()
+	from := sdk.AccAddress(pubKey.Address())
+
+	var msg sdk.Msg = banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, 1)))
+	if timeSensitive {
+		msg = banktypes.NewMsgMultiSend(banktypes.NewInput(from, sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, 1))), nil)
+	}
+
+	txBuilder := txConfig.NewTxBuilder()
+	require.NoError(t, txBuilder.SetMsgs(msg))
+	txBuilder.SetFeeAmount(fee)
+	txBuilder.SetGasLimit(gas)
+	require.NoError(t, txBuilder.SetSignatures(signing.SignatureV2{
+		PubKey: pubKey,
+		Data:   &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_DIRECT},
+	}))
+
+	return txBuilder.GetTx()
+}
+
+// msgsTx is a tx which isn't a sdk.FeeTx.
+type msgsTx []sdk.Msg
+
+func (tx msgsTx) GetMsgs() []sdk.Msg { return tx }
+
+func (msgsTx) GetMsgsV2() ([]protov2.Message, error) { return nil, nil }
+
+func TestTxPriority(t *testing.T) {
+	txConfig := newMempoolTxConfig()
+	txPriority := app.NewTxPriority([]string{timeSensitiveMsg})
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+
+	rps := func(amount sdkmath.Int) sdk.Coins {
+		return sdk.NewCoins(sdk.NewCoin(params.DefaultBondDenom, amount))
+	}
+
+	testCases := []struct {
+		name     string
+		tx       sdk.Tx
+		priority app.TxPriority
+	}{
+		{"gas price", mempoolTx(t, txConfig, "alice", false, rps(sdkmath.NewInt(3)), 2), app.TxPriority{GasPrice: 1_500_000}},
+		{"gas price rounded down", mempoolTx(t, txConfig, "alice", false, rps(sdkmath.NewInt(1)), 3), app.TxPriority{GasPrice: 333_333}},
+		{"no fee", mempoolTx(t, txConfig, "alice", false, nil, 100), app.TxPriority{}},
+		{"fee of another denom", mempoolTx(t, txConfig, "alice", false, sdk.NewCoins(sdk.NewInt64Coin("atom", 100)), 100), app.TxPriority{}},
+		{"zero gas", mempoolTx(t, txConfig, "alice", false, rps(sdkmath.NewInt(100)), 0), app.TxPriority{}},
+		{"gas price above MaxInt64", mempoolTx(t, txConfig, "alice", false, rps(sdkmath.NewIntFromUint64(math.MaxUint64)), 1), app.TxPriority{GasPrice: math.MaxInt64}},
+		{"time sensitive", mempoolTx(t, txConfig, "alice", true, rps(sdkmath.NewInt(1)), 1), app.TxPriority{TimeSensitive: true, GasPrice: 1_000_000}},
+		{"time sensitive without fee", mempoolTx(t, txConfig, "alice", true, nil, 100), app.TxPriority{TimeSensitive: true}},
+		{"not a fee tx", msgsTx{&banktypes.MsgSend{}}, app.TxPriority{}},
+		{"time sensitive not a fee tx", msgsTx{&banktypes.MsgSend{}, &banktypes.MsgMultiSend{}}, app.TxPriority{TimeSensitive: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.priority, txPriority.GetTxPriority(ctx, tc.tx))
+		})
+	}
+}
+
+func TestTxPriorityCompare(t *testing.T) {
+	txPriority := app.NewTxPriority(nil)
+
+	testCases := []struct {
+		name     string
+		a, b     app.TxPriority
+		expected int
+	}{
+		{"higher gas price", app.TxPriority{GasPrice: 2}, app.TxPriority{GasPrice: 1}, 1},
+		{"lower gas price", app.TxPriority{GasPrice: 1}, app.TxPriority{GasPrice: 2}, -1},
+		{"same gas price", app.TxPriority{GasPrice: 1}, app.TxPriority{GasPrice: 1}, 0},
+		{"time sensitive", app.TxPriority{TimeSensitive: true}, app.TxPriority{GasPrice: math.MaxInt64}, 1},
+		{"not time sensitive", app.TxPriority{GasPrice: math.MaxInt64}, app.TxPriority{TimeSensitive: true}, -1},
+		{"time sensitive gas price", app.TxPriority{TimeSensitive: true, GasPrice: 2}, app.TxPriority{TimeSensitive: true, GasPrice: 1}, 1},
+		{"same time sensitive", app.TxPriority{TimeSensitive: true, GasPrice: 1}, app.TxPriority{TimeSensitive: true, GasPrice: 1}, 0},
+		{"min value", txPriority.MinValue, app.TxPriority{}, -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, txPriority.Compare(tc.a, tc.b))
+		})
+	}
+}
+
+func TestMempoolSelect(t *testing.T) {
+	txConfig := newMempoolTxConfig()
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+	mp := mempool.NewPriorityMempool(mempool.PriorityNonceMempoolConfig[app.TxPriority]{
+		TxPriority: app.NewTxPriority([]string{timeSensitiveMsg}),
+	})
+
+	rps := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, amount))
+	}
+
+	// txs in their expected order
+	txs := []sdk.Tx{
+		mempoolTx(t, txConfig, "reveal", true, rps(1), 200),
+		mempoolTx(t, txConfig, "carol", false, rps(5), 100),
+		mempoolTx(t, txConfig, "alice", false, rps(2), 100),
+		mempoolTx(t, txConfig, "bob", false, rps(1), 100),
+		mempoolTx(t, txConfig, "dave", false, nil, 100),
+	}
+
+	for _, i := range []int{3, 4, 1, 0, 2} {
+		require.NoError(t, mp.Insert(ctx, txs[i]))
+	}
+	require.Equal(t, len(txs), mp.CountTx())
+
+	var selected []sdk.Tx
+	for iterator := mp.Select(ctx, nil); iterator != nil; iterator = iterator.Next() {
+		selected = append(selected, iterator.Tx())
+	}
+	require.Equal(t, txs, selected)
+}
+
+func TestNewMempool(t *testing.T) {
+	txConfig := newMempoolTxConfig()
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+
+	// the capacity is read from app.toml
+	mp := app.NewMempool(simtestutil.AppOptionsMap{server.FlagMempoolMaxTxs: 1})
+	require.IsType(t, &mempool.PriorityNonceMempool[app.TxPriority]{}, mp)
+	require.NoError(t, mp.Insert(ctx, mempoolTx(t, txConfig, "alice", false, nil, 100)))
+	require.ErrorIs(t, mp.Insert(ctx, mempoolTx(t, txConfig, "bob", false, nil, 100)), mempool.ErrMempoolTxMaxCapacity)
+
+	// and is unbounded by default
+	mp = app.NewMempool(simtestutil.AppOptionsMap{})
+	require.IsType(t, &mempool.PriorityNonceMempool[app.TxPriority]{}, mp)
+	require.NoError(t, mp.Insert(ctx, mempoolTx(t, txConfig, "alice", false, nil, 100)))
+}
+
+// proposalTxVerifier decodes the proposal txs without verifying them.
+type proposalTxVerifier struct {
+	client.TxConfig
+}
+
+func (v proposalTxVerifier) PrepareProposalVerifyTx(tx sdk.Tx) ([]byte, error) {
+	return v.TxEncoder()(tx)
+}
+
+func (v proposalTxVerifier) ProcessProposalVerifyTx(txBz []byte) (sdk.Tx, error) {
+	return v.TxDecoder()(txBz)
+}
+
+func (v proposalTxVerifier) TxDecode(txBz []byte) (sdk.Tx, error) {
+	return v.TxDecoder()(txBz)
+}
+
+func (v proposalTxVerifier) TxEncode(tx sdk.Tx) ([]byte, error) {
+	return v.TxEncoder()(tx)
+}
+
+func TestNewMempoolDisabled(t *testing.T) {
+	txConfig := newMempoolTxConfig()
+	ctx := sdk.NewContext(nil, cmtproto.Header{}, false, log.NewNopLogger())
+
+	// max-txs = -1 disables the app mempool
+	mp := app.NewMempool(simtestutil.AppOptionsMap{server.FlagMempoolMaxTxs: -1})
+	require.Equal(t, mempool.NoOpMempool{}, mp)
+
+	txBz, err := txConfig.TxEncoder()(mempoolTx(t, txConfig, "alice", false, nil, 100))
+	require.NoError(t, err)
+
+	// so the proposals are built from the txs of the CometBFT mempool
+	proposalHandler := baseapp.NewDefaultProposalHandler(mp, proposalTxVerifier{txConfig})
+	res, err := proposalHandler.PrepareProposalHandler()(ctx, &abci.RequestPrepareProposal{Txs: [][]byte{txBz}, MaxTxBytes: 1 << 20})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{txBz}, res.Txs)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"math/big"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/hashicorp/go-metrics"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// flagTelemetryEnabled is the app.toml option enabling the telemetry.
+const flagTelemetryEnabled = "telemetry.enabled"
+
+// MetricsModuleAccounts are the module accounts whose balances are exported
+// as gauges after every block.
+var MetricsModuleAccounts = []string{
+	authtypes.FeeCollectorName,
+	distrtypes.ModuleName,
+	stakingtypes.BondedPoolName,
+	stakingtypes.NotBondedPoolName,
+}
+
+// FinalizeBlock finalizes the block and, when the telemetry is enabled,
+// counts the block and its txs and messages.
+func (app *RPSApp) FinalizeBlock(req *abci.RequestFinalizeBlock) (*abci.ResponseFinalizeBlock, error) {
+	res, err := app.App.FinalizeBlock(req)
+	if err == nil && app.telemetryEnabled {
+		app.emitBlockMetrics(req, res)
+	}
+
+	return res, err
+}
+
+// Commit commits the block and, when the telemetry is enabled, exports the
+// committed balances of the MetricsModuleAccounts.
+func (app *RPSApp) Commit() (*abci.ResponseCommit, error) {
+	res, err := app.App.Commit()
+	if err == nil && app.telemetryEnabled {
+		app.emitModuleAccountMetrics(app.NewUncachedContext(false, cmtproto.Header{}))
+	}
+
+	return res, err
+}
+
+func (app *RPSApp) emitBlockMetrics(req *abci.RequestFinalizeBlock, res *abci.ResponseFinalizeBlock) {
+	telemetry.IncrCounter(1, "rps", "blocks")
+
+	txDecoder := app.txConfig.TxDecoder()
+	for i, txBytes := range req.Txs {
+		result := "success"
+		if i < len(res.TxResults) && !res.TxResults[i].IsOK() {
+			result = "failure"
+		}
+		telemetry.IncrCounterWithLabels([]string{"rps", "txs"}, 1, []metrics.Label{telemetry.NewLabel("result", result)})
+
+		tx, err := txDecoder(txBytes)
+		if err != nil {
+			continue
+		}
+
+		for _, msg := range tx.GetMsgs() {
+			telemetry.IncrCounterWithLabels(
+				[]string{"rps", "msgs"},
+				1,
+				[]metrics.Label{telemetry.NewLabel("type", sdk.MsgTypeURL(msg)), telemetry.NewLabel("result", result)},
+			)
+		}
+	}
+}
+
+func (app *RPSApp) emitModuleAccountMetrics(ctx sdk.Context) {
+	for _, name := range MetricsModuleAccounts {
+		addr := authtypes.NewModuleAddress(name)
+
+		// always export the bond denom so that an emptied account reports 0
+		balances := app.BankKeeper.GetAllBalances(ctx, addr)
+		if balances.AmountOf(params.DefaultBondDenom).IsZero() {
+			balances = append(sdk.Coins{sdk.NewInt64Coin(params.DefaultBondDenom, 0)}, balances...)
+		}
+
+		for _, coin := range balances {
+			amount, _ := new(big.Float).SetInt(coin.Amount.BigInt()).Float32()
+			telemetry.SetGaugeWithLabels(
+				[]string{"rps", "module_account", "balance"},
+				amount,
+				[]metrics.Label{telemetry.NewLabel("module", name), telemetry.NewLabel("denom", coin.Denom)},
+			)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"math/big"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+)
+
+// DefaultHalvingSchedule is the supply schedule of rps: 10rps per block,
+// halved every 25246080 blocks, i.e. 4 years at the default x/mint
+// blocks_per_year. The first era mints about 63.1M rps a year and the total
+// minted supply is bounded by about 505M rps.
+var DefaultHalvingSchedule = HalvingSchedule{
+	InitialBlockReward: math.NewInt(10),
+	HalvingInterval:    25_246_080,
+}
+
+// HalvingSchedule is a fixed supply schedule: every block mints a reward
+// which is halved every HalvingInterval blocks. The total minted supply is
+// therefore bounded by 2 * InitialBlockReward * HalvingInterval. The interval
+// is a block count rather than a number of years of the blocks_per_year
+// param of x/mint, which governance can change, so that a param change
+// neither moves the chain to another era nor changes the bound.
+type HalvingSchedule struct {
+	InitialBlockReward math.Int
+	HalvingInterval    int64
+}
+
+// Era returns the number of halvings that happened at the given height.
+func (s HalvingSchedule) Era(height int64) int64 {
+	if height < 0 {
+		return 0
+	}
+
+	return height / s.HalvingInterval
+}
+
+// BlockReward returns the amount minted by the block at the given height.
+func (s HalvingSchedule) BlockReward(height int64) math.Int {
+	era := s.Era(height)
+	if era >= int64(s.InitialBlockReward.BigInt().BitLen()) {
+		return math.ZeroInt()
+	}
+
+	return math.NewIntFromBigInt(new(big.Int).Rsh(s.InitialBlockReward.BigInt(), uint(era)))
+}
+
+// NextHalvingHeight returns the first height after the given one at which
+// the block reward is halved.
+func (s HalvingSchedule) NextHalvingHeight(height int64) int64 {
+	return (s.Era(height) + 1) * s.HalvingInterval
+}
+
+// AnnualProvisions returns the amount minted over a year at the block reward
+// of the given height.
+func (s HalvingSchedule) AnnualProvisions(height int64, params minttypes.Params) math.LegacyDec {
+	return math.LegacyNewDecFromInt(s.BlockReward(height).MulRaw(int64(params.BlocksPerYear)))
+}
+
+// InflationCalculationFn returns the x/mint inflation function following the
+// schedule. x/mint derives the minted amount from the inflation rate and the
+// staking token supply, so the rate is chosen such that each block mints the
+// scheduled reward, up to decimal rounding.
+func (s HalvingSchedule) InflationCalculationFn(stakingKeeper minttypes.StakingKeeper) minttypes.InflationCalculationFn {
+	return func(ctx context.Context, _ minttypes.Minter, params minttypes.Params, _ math.LegacyDec) math.LegacyDec {
+		totalSupply, err := stakingKeeper.StakingTokenSupply(ctx)
+		if err != nil {
+			panic(err)
+		}
+
+		if !totalSupply.IsPositive() {
+			return math.LegacyZeroDec()
+		}
+
+		height := sdk.UnwrapSDKContext(ctx).BlockHeight()
+
+		// round up so that the truncated block provision isn't a unit short
+		return s.AnnualProvisions(height, params).QuoRoundUp(math.LegacyNewDecFromInt(totalSupply))
+	}
+}
+
+// ProvideInflationCalculationFn provides the x/mint inflation function of the
+// DefaultHalvingSchedule.
+func ProvideInflationCalculationFn(stakingKeeper minttypes.StakingKeeper) minttypes.InflationCalculationFn {
+	return DefaultHalvingSchedule.InflationCalculationFn(stakingKeeper)
+}
@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+
+	"github.com/0xlb/rps-chain/app"
+)
+
+func TestHalvingSchedule(t *testing.T) {
+	params := minttypes.DefaultParams()
+	schedule := app.DefaultHalvingSchedule
+	interval := schedule.HalvingInterval
+	// 4 years at the default blocks_per_year
+	require.Equal(t, 4*int64(params.BlocksPerYear), interval)
+
+	testCases := []struct {
+		height      int64
+		era         int64
+		reward      int64
+		nextHalving int64
+	}{
+		{height: 0, era: 0, reward: 10, nextHalving: interval},
+		{height: 1, era: 0, reward: 10, nextHalving: interval},
+		{height: interval - 1, era: 0, reward: 10, nextHalving: interval},
+		{height: interval, era: 1, reward: 5, nextHalving: 2 * interval},
+		{height: 2*interval - 1, era: 1, reward: 5, nextHalving: 2 * interval},
+		{height: 2 * interval, era: 2, reward: 2, nextHalving: 3 * interval},
+		{height: 3 * interval, era: 3, reward: 1, nextHalving: 4 * interval},
+		{height: 4*interval - 1, era: 3, reward: 1, nextHalving: 4 * interval},
+		// 10 has 4 bits, so the reward is exhausted from the fourth halving on
+		{height: 4 * interval, era: 4, reward: 0, nextHalving: 5 * interval},
+		{height: 100 * interval, era: 100, reward: 0, nextHalving: 101 * interval},
+		{height: -1, era: 0, reward: 10, nextHalving: interval},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.era, schedule.Era(tc.height), "era at height %d", tc.height)
+		require.Equal(t, math.NewInt(tc.reward), schedule.BlockReward(tc.height), "reward at height %d", tc.height)
+		require.Equal(t, tc.nextHalving, schedule.NextHalvingHeight(tc.height), "next halving at height %d", tc.height)
+		require.Equal(t, math.LegacyNewDec(tc.reward*int64(params.BlocksPerYear)), schedule.AnnualProvisions(tc.height, params), "annual provisions at height %d", tc.height)
+	}
+}
+
+func TestHalvingScheduleBlocksPerYear(t *testing.T) {
+	schedule := app.DefaultHalvingSchedule
+	interval := schedule.HalvingInterval
+	inflationFn := schedule.InflationCalculationFn(stakingTokenSupply(math.NewInt(10_000_000)))
+
+	// a governance change of blocks_per_year keeps the era and the block
+	// reward of every height, only the annual provisions follow it
+	for _, blocksPerYear := range []uint64{100, 6_311_520, 2 * 6_311_520, 10 * 6_311_520} {
+		params := minttypes.DefaultParams()
+		params.BlocksPerYear = blocksPerYear
+
+		for _, height := range []int64{1, interval - 1, interval, 2*interval + 1} {
+			ctx := sdk.Context{}.WithBlockHeight(height)
+
+			minter := minttypes.DefaultInitialMinter()
+			minter.Inflation = inflationFn(ctx, minter, params, math.LegacyZeroDec())
+			minter.AnnualProvisions = minter.NextAnnualProvisions(params, math.NewInt(10_000_000))
+
+			require.Equal(t, height/interval, schedule.Era(height), "era at height %d", height)
+			require.Equal(t, schedule.BlockReward(height), minter.BlockProvision(params).Amount,
+				"block provision at height %d with %d blocks per year", height, blocksPerYear)
+			require.Equal(t, math.LegacyNewDecFromInt(schedule.BlockReward(height).MulRaw(int64(blocksPerYear))), schedule.AnnualProvisions(height, params))
+		}
+	}
+}
+
+// stakingTokenSupply is an x/mint staking keeper of a fixed token supply.
+type stakingTokenSupply math.Int
+
+func (s stakingTokenSupply) StakingTokenSupply(context.Context) (math.Int, error) {
+	return math.Int(s), nil
+}
+
+func (stakingTokenSupply) BondedRatio(context.Context) (math.LegacyDec, error) {
+	return math.LegacyZeroDec(), nil
+}
+
+func TestHalvingInflationCalculationFn(t *testing.T) {
+	params := minttypes.DefaultParams()
+	schedule := app.DefaultHalvingSchedule
+	interval := schedule.HalvingInterval
+
+	for _, supply := range []int64{1, 3, 10_000_000, 123_456_789_012} {
+		inflationFn := schedule.InflationCalculationFn(stakingTokenSupply(math.NewInt(supply)))
+
+		for _, height := range []int64{1, interval - 1, interval, 3 * interval, 4 * interval} {
+			ctx := sdk.Context{}.WithBlockHeight(height)
+
+			minter := minttypes.DefaultInitialMinter()
+			minter.Inflation = inflationFn(ctx, minter, params, math.LegacyZeroDec())
+			minter.AnnualProvisions = minter.NextAnnualProvisions(params, math.NewInt(supply))
+
+			// x/mint mints the scheduled reward whatever the supply
+			require.Equal(t, schedule.BlockReward(height), minter.BlockProvision(params).Amount,
+				"block provision at height %d with supply %d", height, supply)
+		}
+	}
+
+	// nothing is minted without staking tokens
+	inflationFn := schedule.InflationCalculationFn(stakingTokenSupply(math.ZeroInt()))
+	require.True(t, inflationFn(sdk.Context{}.WithBlockHeight(1), minttypes.DefaultInitialMinter(), params, math.LegacyZeroDec()).IsZero())
+}
@@ -0,0 +1,321 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	dbm "github.com/cosmos/cosmos-db"
+
+	"cosmossdk.io/depinject"
+	pruningtypes "cosmossdk.io/store/pruning/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/server"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/testutil/network"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+
+	"github.com/0xlb/rps-chain/app"
+	"github.com/0xlb/rps-chain/app/ante"
+	"github.com/0xlb/rps-chain/app/params"
+)
+
+// DefaultGasLimit is the gas limit of the txs broadcast by the Network.
+const DefaultGasLimit = 400_000
+
+// txInclusionBlocks is the number of blocks waited for a tx to be included.
+const txInclusionBlocks = 5
+
+// VotingPeriod is the voting period of the governance proposals.
+const VotingPeriod = 5 * time.Second
+
+// DefaultConfig returns the configuration of an in-process network of
+// numValidators RPSApp validators using the rps denoms. Each validator keeps
+// its state in the database of its home, where the node commands find it once
+// the network is cleaned up.
+func DefaultConfig(numValidators int) (network.Config, error) {
+	// the x/upgrade keeper reads the upgrade info of the home of the app
+	// options, creating its data directory: the validators have their own
+	// homes, the config is built with a temporary one
+	tempHome, err := os.MkdirTemp("", "rpsd")
+	if err != nil {
+		return network.Config{}, err
+	}
+	defer os.RemoveAll(tempHome)
+
+	cfg, err := network.DefaultConfigWithAppConfig(depinject.Configs(
+		app.AppConfig(),
+		depinject.Supply(simtestutil.NewAppOptionsWithFlagHome(tempHome)),
+	))
+	if err != nil {
+		return network.Config{}, err
+	}
+
+	// the IBC modules aren't part of app wiring
+	ibcModules := module.NewManagerFromMap(app.RegisterIBC(cfg.InterfaceRegistry))
+	for name, state := range module.NewBasicManagerFromManager(ibcModules, nil).DefaultGenesis(cfg.Codec) {
+		cfg.GenesisState[name] = state
+	}
+
+	// the proposals are voted within a few blocks
+	var govGenState govv1.GenesisState
+	cfg.Codec.MustUnmarshalJSON(cfg.GenesisState[govtypes.ModuleName], &govGenState)
+	votingPeriod, expeditedVotingPeriod := VotingPeriod, VotingPeriod/2
+	govGenState.Params.VotingPeriod = &votingPeriod
+	govGenState.Params.ExpeditedVotingPeriod = &expeditedVotingPeriod
+	cfg.GenesisState[govtypes.ModuleName] = cfg.Codec.MustMarshalJSON(&govGenState)
+
+	// the module default genesis states use the bond denom of the sdk
+	if err := app.SetDenomGenesis(cfg.Codec, cfg.GenesisState); err != nil {
+		return network.Config{}, err
+	}
+
+	cfg.NumValidators = numValidators
+	cfg.BondDenom = params.DefaultBondDenom
+	// the fees are enforced by the ante handler minimum fees
+	cfg.MinGasPrices = "0" + params.DefaultBondDenom
+	cfg.TimeoutCommit = 500 * time.Millisecond
+
+	return cfg, nil
+}
+
+// Network is an in-process network of RPSApp validators, along with a test
+// keyring holding funded accounts.
+type Network struct {
+	*network.Network
+
+	// Keyring holds the keys of the funded accounts.
+	Keyring keyring.Keyring
+
+	mu          sync.Mutex
+	apps        map[string]*app.RPSApp
+	cleanupOnce sync.Once
+}
+
+// New starts an in-process network with cfg. Every name of accounts is a key
+// of the network keyring funded at genesis with cfg.AccountTokens of the
+// bond denom. The network is cleaned up at the end of the test.
+func New(t *testing.T, cfg network.Config, accounts ...string) (*Network, error) {
+	t.Helper()
+
+	n := &Network{
+		Keyring: keyring.NewInMemory(cfg.Codec),
+		apps:    make(map[string]*app.RPSApp),
+	}
+
+	if err := n.addGenesisAccounts(&cfg, accounts); err != nil {
+		return nil, err
+	}
+
+	cfg.AppConstructor = func(val network.ValidatorI) servertypes.Application {
+		home := val.GetCtx().Config.RootDir
+
+		// the network doesn't set the home of the validator app options, where
+		// x/upgrade reads and writes its upgrade info
+		val.GetCtx().Viper.Set(flags.FlagHome, home)
+
+		// the database the node commands open, see server.openDB
+		db, err := dbm.NewDB("application", server.GetAppDBBackend(val.GetCtx().Viper), filepath.Join(home, "data"))
+		if err != nil {
+			panic(err)
+		}
+
+		rpsApp, err := app.NewRPSApp(
+			val.GetCtx().Logger,
+			db,
+			nil,
+			true,
+			val.GetCtx().Viper,
+			baseapp.SetPruning(pruningtypes.NewPruningOptionsFromString(val.GetAppConfig().Pruning)),
+			baseapp.SetMinGasPrices(val.GetAppConfig().MinGasPrices),
+			baseapp.SetChainID(cfg.ChainID),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		n.mu.Lock()
+		n.apps[val.GetCtx().Config.Moniker] = rpsApp
+		n.mu.Unlock()
+
+		return rpsApp
+	}
+
+	// the validator homes outlive Cleanup, the test removes the temp dir
+	cfg.CleanupDir = false
+
+	var err error
+	n.Network, err = network.New(t, t.TempDir(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(n.Cleanup)
+
+	return n, nil
+}
+
+// Cleanup stops the validators and closes their apps. It may be called
+// before the end of the test, e.g. to run the node commands against the
+// database of a validator.
+func (n *Network) Cleanup() {
+	n.cleanupOnce.Do(n.Network.Cleanup)
+}
+
+// addGenesisAccounts creates the accounts in the network keyring and funds
+// them in the genesis state of cfg.
+func (n *Network) addGenesisAccounts(cfg *network.Config, names []string) error {
+	var (
+		authGenState authtypes.GenesisState
+		bankGenState banktypes.GenesisState
+	)
+	cfg.Codec.MustUnmarshalJSON(cfg.GenesisState[authtypes.ModuleName], &authGenState)
+	cfg.Codec.MustUnmarshalJSON(cfg.GenesisState[banktypes.ModuleName], &bankGenState)
+
+	accounts := make([]authtypes.GenesisAccount, 0, len(names))
+	for _, name := range names {
+		record, _, err := n.Keyring.NewMnemonic(name, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+		if err != nil {
+			return fmt.Errorf("failed to create account %s: %w", name, err)
+		}
+
+		addr, err := record.GetAddress()
+		if err != nil {
+			return err
+		}
+
+		accounts = append(accounts, authtypes.NewBaseAccount(addr, nil, 0, 0))
+		bankGenState.Balances = append(bankGenState.Balances, banktypes.Balance{
+			Address: addr.String(),
+			Coins:   sdk.NewCoins(sdk.NewCoin(cfg.BondDenom, cfg.AccountTokens)),
+		})
+	}
+
+	packed, err := authtypes.PackAccounts(accounts)
+	if err != nil {
+		return err
+	}
+	authGenState.Accounts = append(authGenState.Accounts, packed...)
+
+	cfg.GenesisState[authtypes.ModuleName] = cfg.Codec.MustMarshalJSON(&authGenState)
+	cfg.GenesisState[banktypes.ModuleName] = cfg.Codec.MustMarshalJSON(&bankGenState)
+
+	return nil
+}
+
+// App returns the RPSApp of the i-th validator.
+func (n *Network) App(i int) *app.RPSApp {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.apps[n.Validators[i].Moniker]
+}
+
+// BasicManager returns the module basics of the network apps.
+func (n *Network) BasicManager() module.BasicManager {
+	return module.NewBasicManagerFromManager(n.App(0).ModuleManager, nil)
+}
+
+// ClientCtx returns the client context of the first validator, using the
+// network keyring.
+func (n *Network) ClientCtx() client.Context {
+	return n.Validators[0].ClientCtx.WithKeyring(n.Keyring)
+}
+
+// Address returns the address of the named account of the network keyring.
+func (n *Network) Address(name string) (sdk.AccAddress, error) {
+	record, err := n.Keyring.Key(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.GetAddress()
+}
+
+// BroadcastTx signs msgs with the named account, paying the minimum fee of
+// the ante handler, broadcasts the tx and waits for its inclusion. A tx
+// rejected by CheckTx or failing in its block is returned along with an error.
+func (n *Network) BroadcastTx(from string, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	clientCtx := n.ClientCtx()
+
+	addr, err := n.Address(from)
+	if err != nil {
+		return nil, err
+	}
+	clientCtx = clientCtx.WithFrom(from).WithFromName(from).WithFromAddress(addr)
+
+	txf, err := clienttx.Factory{}.
+		WithChainID(n.Config.ChainID).
+		WithKeybase(n.Keyring).
+		WithTxConfig(n.Config.TxConfig).
+		WithAccountRetriever(n.Config.AccountRetriever).
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithGas(DefaultGasLimit).
+		WithFees(ante.DefaultParams().MinFee(msgs).String()).
+		Prepare(clientCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	txBuilder, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := clienttx.Sign(context.Background(), txf, from, txBuilder, true); err != nil {
+		return nil, err
+	}
+
+	txBytes, err := n.Config.TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := clientCtx.BroadcastTxSync(txBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Code != 0 {
+		return res, fmt.Errorf("tx %s rejected with code %d: %s", res.TxHash, res.Code, res.RawLog)
+	}
+
+	return n.WaitForTx(res.TxHash)
+}
+
+// WaitForTx waits for the tx of the given hash to be included in a block and
+// returns its result, or an error if the tx failed.
+func (n *Network) WaitForTx(hash string) (*sdk.TxResponse, error) {
+	var res *sdk.TxResponse
+	err := n.RetryForBlocks(func() error {
+		var err error
+		res, err = authtx.QueryTx(n.ClientCtx(), hash)
+		return err
+	}, txInclusionBlocks)
+	if err != nil {
+		return nil, fmt.Errorf("tx %s not included after %d blocks: %w", hash, txInclusionBlocks, err)
+	}
+
+	if res.Code != 0 {
+		return res, fmt.Errorf("tx %s failed with code %d: %s", hash, res.Code, res.RawLog)
+	}
+
+	return res, nil
+}
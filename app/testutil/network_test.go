@@ -0,0 +1,310 @@
+package testutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"cosmossdk.io/math"
+
+	svrcmd "github.com/cosmos/cosmos-sdk/server/cmd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/cosmos/cosmos-sdk/x/group"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	ibctransfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+
+	"github.com/0xlb/rps-chain/app/params"
+	"github.com/0xlb/rps-chain/app/testutil"
+	"github.com/0xlb/rps-chain/cmd/rpsd/cmd"
+)
+
+type NetworkTestSuite struct {
+	suite.Suite
+
+	network *testutil.Network
+}
+
+func TestNetworkTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in-process network tests in short mode")
+	}
+
+	suite.Run(t, new(NetworkTestSuite))
+}
+
+func (s *NetworkTestSuite) SetupSuite() {
+	cfg, err := testutil.DefaultConfig(2)
+	s.Require().NoError(err)
+
+	s.network, err = testutil.New(s.T(), cfg, "alice", "bob", "carol")
+	s.Require().NoError(err)
+}
+
+func (s *NetworkTestSuite) address(name string) sdk.AccAddress {
+	addr, err := s.network.Address(name)
+	s.Require().NoError(err)
+
+	return addr
+}
+
+func (s *NetworkTestSuite) balance(addr sdk.AccAddress) math.Int {
+	res, err := banktypes.NewQueryClient(s.network.ClientCtx()).Balance(
+		context.Background(),
+		banktypes.NewQueryBalanceRequest(addr, params.DefaultBondDenom),
+	)
+	s.Require().NoError(err)
+
+	return res.Balance.Amount
+}
+
+func (s *NetworkTestSuite) TestBankSend() {
+	alice, bob := s.address("alice"), s.address("bob")
+	aliceBefore, bobBefore := s.balance(alice), s.balance(bob)
+
+	amount := sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, 1000))
+	_, err := s.network.BroadcastTx("alice", banktypes.NewMsgSend(alice, bob, amount))
+	s.Require().NoError(err)
+
+	// alice pays the 1rps minimum fee on top of the amount
+	s.Require().Equal(aliceBefore.SubRaw(1001), s.balance(alice))
+	s.Require().Equal(bobBefore.AddRaw(1000), s.balance(bob))
+}
+
+func (s *NetworkTestSuite) TestBankSendWithoutFundsFails() {
+	alice, bob := s.address("alice"), s.address("bob")
+
+	amount := sdk.NewCoins(sdk.NewCoin(params.DefaultBondDenom, s.balance(bob).AddRaw(1)))
+	_, err := s.network.BroadcastTx("bob", banktypes.NewMsgSend(bob, alice, amount))
+	s.Require().ErrorContains(err, "insufficient funds")
+}
+
+func (s *NetworkTestSuite) TestDelegation() {
+	carol := s.address("carol")
+	val := s.network.Validators[1].ValAddress
+
+	amount := sdk.NewInt64Coin(params.DefaultBondDenom, 1_000_000)
+	_, err := s.network.BroadcastTx("carol", stakingtypes.NewMsgDelegate(carol.String(), val.String(), amount))
+	s.Require().NoError(err)
+
+	res, err := stakingtypes.NewQueryClient(s.network.ClientCtx()).Delegation(context.Background(), &stakingtypes.QueryDelegationRequest{
+		DelegatorAddr: carol.String(),
+		ValidatorAddr: val.String(),
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(amount, res.DelegationResponse.Balance)
+}
+
+func (s *NetworkTestSuite) TestRewardWithdrawal() {
+	alice := s.address("alice")
+	val := s.network.Validators[0].ValAddress
+
+	// a large delegation earns a fraction of the 10rps block reward
+	amount := sdk.NewCoin(params.DefaultBondDenom, s.network.Config.BondedTokens)
+	_, err := s.network.BroadcastTx("alice", stakingtypes.NewMsgDelegate(alice.String(), val.String(), amount))
+	s.Require().NoError(err)
+
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(s.network.WaitForNextBlock())
+	}
+
+	res, err := s.network.BroadcastTx("alice", distrtypes.NewMsgWithdrawDelegatorReward(alice.String(), val.String()))
+	s.Require().NoError(err)
+
+	var withdrawn sdk.Coins
+	for _, event := range res.Events {
+		if event.Type != distrtypes.EventTypeWithdrawRewards {
+			continue
+		}
+
+		for _, attr := range event.Attributes {
+			if attr.Key == sdk.AttributeKeyAmount {
+				withdrawn, err = sdk.ParseCoinsNormalized(attr.Value)
+				s.Require().NoError(err)
+			}
+		}
+	}
+	s.Require().True(withdrawn.AmountOf(params.DefaultBondDenom).IsPositive(), "withdrawn %s", withdrawn)
+}
+
+func (s *NetworkTestSuite) TestGovUpdatesTransferParams() {
+	bob := s.address("bob")
+	govAddr := authtypes.NewModuleAddress(govtypes.ModuleName)
+
+	// bob holds most of the bonded stake, so his vote passes the proposal
+	stake := sdk.NewCoin(params.DefaultBondDenom, s.network.Config.BondedTokens.MulRaw(int64(len(s.network.Validators)+1)))
+	_, err := s.network.BroadcastTx("bob", stakingtypes.NewMsgDelegate(bob.String(), s.network.Validators[0].ValAddress.String(), stake))
+	s.Require().NoError(err)
+
+	// the IBC authority is the gov module account
+	msgUpdateParams := &ibctransfertypes.MsgUpdateParams{
+		Signer: govAddr.String(),
+		Params: ibctransfertypes.Params{SendEnabled: false, ReceiveEnabled: true},
+	}
+	deposit := sdk.NewCoins(sdk.NewCoin(params.DefaultBondDenom, govv1.DefaultMinDepositTokens))
+	msgSubmit, err := govv1.NewMsgSubmitProposal([]sdk.Msg{msgUpdateParams}, deposit, bob.String(), "", "disable transfers", "disable the ICS-20 transfers", false)
+	s.Require().NoError(err)
+
+	res, err := s.network.BroadcastTx("bob", msgSubmit)
+	s.Require().NoError(err)
+
+	var proposalID uint64
+	for _, event := range res.Events {
+		for _, attr := range event.Attributes {
+			if event.Type == govtypes.EventTypeSubmitProposal && attr.Key == govtypes.AttributeKeyProposalID {
+				proposalID, err = strconv.ParseUint(attr.Value, 10, 64)
+				s.Require().NoError(err)
+			}
+		}
+	}
+	s.Require().NotZero(proposalID)
+
+	_, err = s.network.BroadcastTx("bob", govv1.NewMsgVote(bob, proposalID, govv1.OptionYes, ""))
+	s.Require().NoError(err)
+
+	govClient := govv1.NewQueryClient(s.network.ClientCtx())
+	s.Require().NoError(s.network.RetryForBlocks(func() error {
+		proposal, err := govClient.Proposal(context.Background(), &govv1.QueryProposalRequest{ProposalId: proposalID})
+		if err != nil {
+			return err
+		}
+		if proposal.Proposal.Status != govv1.StatusPassed {
+			return fmt.Errorf("proposal %d is %s", proposalID, proposal.Proposal.Status)
+		}
+
+		return nil
+	}, int(2*testutil.VotingPeriod/s.network.Config.TimeoutCommit)))
+
+	transferParams, err := ibctransfertypes.NewQueryClient(s.network.ClientCtx()).Params(context.Background(), &ibctransfertypes.QueryParamsRequest{})
+	s.Require().NoError(err)
+	s.Require().False(transferParams.Params.SendEnabled)
+	s.Require().True(transferParams.Params.ReceiveEnabled)
+}
+
+func (s *NetworkTestSuite) TestGroupPolicyPayout() {
+	alice, bob, carol := s.address("alice"), s.address("bob"), s.address("carol")
+
+	// a team of alice and bob, whose policy account is paid out by a proposal
+	// approved by both of them
+	msgCreate, err := group.NewMsgCreateGroupWithPolicy(
+		alice.String(),
+		[]group.MemberRequest{{Address: alice.String(), Weight: "1"}, {Address: bob.String(), Weight: "1"}},
+		"team",
+		"team policy",
+		false,
+		group.NewThresholdDecisionPolicy("2", time.Minute, 0),
+	)
+	s.Require().NoError(err)
+	_, err = s.network.BroadcastTx("alice", msgCreate)
+	s.Require().NoError(err)
+
+	res, err := group.NewQueryClient(s.network.ClientCtx()).GroupPoliciesByAdmin(context.Background(), &group.QueryGroupPoliciesByAdminRequest{
+		Admin: alice.String(),
+	})
+	s.Require().NoError(err)
+	s.Require().Len(res.GroupPolicies, 1)
+	policy, err := sdk.AccAddressFromBech32(res.GroupPolicies[0].Address)
+	s.Require().NoError(err)
+
+	pot := sdk.NewCoins(sdk.NewInt64Coin(params.DefaultBondDenom, 1000))
+	_, err = s.network.BroadcastTx("alice", banktypes.NewMsgSend(alice, policy, pot))
+	s.Require().NoError(err)
+
+	carolBefore := s.balance(carol)
+
+	msgProposal, err := group.NewMsgSubmitProposal(
+		policy.String(),
+		[]string{alice.String()},
+		[]sdk.Msg{banktypes.NewMsgSend(policy, carol, pot)},
+		"",
+		group.Exec_EXEC_UNSPECIFIED,
+		"pay carol",
+		"",
+	)
+	s.Require().NoError(err)
+	_, err = s.network.BroadcastTx("alice", msgProposal)
+	s.Require().NoError(err)
+
+	for _, voter := range []string{"alice", "bob"} {
+		_, err = s.network.BroadcastTx(voter, &group.MsgVote{
+			ProposalId: 1,
+			Voter:      s.address(voter).String(),
+			Option:     group.VOTE_OPTION_YES,
+		})
+		s.Require().NoError(err)
+	}
+
+	// the proposal is accepted as soon as the threshold is reached
+	_, err = s.network.BroadcastTx("bob", &group.MsgExec{ProposalId: 1, Executor: bob.String()})
+	s.Require().NoError(err)
+
+	s.Require().Equal(carolBefore.AddRaw(1000), s.balance(carol))
+	s.Require().True(s.balance(policy).IsZero())
+}
+
+// TestExport exports the state of a stopped validator at a past height with
+// rpsd export, and checks that it is a valid genesis.
+func TestExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in-process network tests in short mode")
+	}
+
+	cfg, err := testutil.DefaultConfig(1)
+	require.NoError(t, err)
+
+	n, err := testutil.New(t, cfg, "carol")
+	require.NoError(t, err)
+
+	height, err := n.WaitForHeight(3)
+	require.NoError(t, err)
+
+	carol, err := n.Address("carol")
+	require.NoError(t, err)
+	home := n.Validators[0].Ctx.Config.RootDir
+
+	// the node must be stopped to open its database
+	n.Cleanup()
+
+	output := filepath.Join(t.TempDir(), "genesis.json")
+	rootCmd := cmd.NewRootCmd()
+	rootCmd.SetArgs([]string{
+		"export",
+		"--home", home,
+		"--height", strconv.FormatInt(height-1, 10),
+		"--output-document", output,
+	})
+	require.NoError(t, svrcmd.Execute(rootCmd, "", home))
+
+	appGenesis, err := genutiltypes.AppGenesisFromFile(output)
+	require.NoError(t, err)
+	require.Equal(t, height, appGenesis.InitialHeight)
+	require.Len(t, appGenesis.Consensus.Validators, 1)
+
+	var genesis map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(appGenesis.AppState, &genesis))
+	require.NoError(t, n.BasicManager().ValidateGenesis(cfg.Codec, cfg.TxConfig, genesis))
+
+	var bankGenesis banktypes.GenesisState
+	require.NoError(t, cfg.Codec.UnmarshalJSON(genesis[banktypes.ModuleName], &bankGenesis))
+	found := false
+	for _, balance := range bankGenesis.Balances {
+		if balance.Address == carol.String() {
+			found = true
+			require.Equal(t, cfg.AccountTokens, balance.Coins.AmountOf(params.DefaultBondDenom))
+		}
+	}
+	require.True(t, found, "account %s not exported", carol)
+}
@@ -0,0 +1,217 @@
+package testutil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/core/appconfig"
+	"cosmossdk.io/depinject"
+	"cosmossdk.io/log"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/runtime"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+
+	"github.com/0xlb/rps-chain/app"
+)
+
+// blockInterval is the time between the blocks of the upgrade tests.
+const blockInterval = 5 * time.Second
+
+// UpgradeTest describes an upgrade from the previous version of the chain,
+// started from a fixture genesis file, to the current RPSApp.
+type UpgradeTest struct {
+	// Name is the name of the upgrade plan.
+	Name string
+	// PreviousAppConfig is the app.yaml of the previous version.
+	PreviousAppConfig []byte
+	// GenesisFile is the fixture genesis file of the previous version.
+	GenesisFile string
+	// Height is the upgrade height. The previous version executes the
+	// blocks before it.
+	Height int64
+}
+
+// UpgradeResult is the state of the chain after an upgrade test.
+type UpgradeResult struct {
+	// App is the RPSApp having executed the upgrade block.
+	App *app.RPSApp
+	// Genesis is the fixture genesis of the chain.
+	Genesis *genutiltypes.AppGenesis
+}
+
+// RunUpgrade starts the previous version of the chain from the fixture
+// genesis and executes its blocks until the upgrade height, as the nodes
+// halted with --halt-height do. The RPSApp then restarts on the same database
+// and executes the upgrade block, the compiled-in height of the upgrade on the
+// fixture chain being the test height.
+func RunUpgrade(t *testing.T, test UpgradeTest) UpgradeResult {
+	t.Helper()
+
+	appGenesis, err := genutiltypes.AppGenesisFromFile(test.GenesisFile)
+	require.NoError(t, err)
+
+	home := t.TempDir()
+	db := dbm.NewMemDB()
+
+	previousApp := newPreviousApp(t, test.PreviousAppConfig, db, home, appGenesis.ChainID)
+
+	consensusParams := appGenesis.Consensus.Params.ToProto()
+	_, err = previousApp.InitChain(&abci.RequestInitChain{
+		Time:            appGenesis.GenesisTime,
+		ChainId:         appGenesis.ChainID,
+		ConsensusParams: &consensusParams,
+		AppStateBytes:   appGenesis.AppState,
+		InitialHeight:   appGenesis.InitialHeight,
+	})
+	require.NoError(t, err)
+
+	for height := appGenesis.InitialHeight; height < test.Height; height++ {
+		_, err := FinalizeAndCommit(previousApp, height, heightTime(appGenesis.GenesisTime, height), nil)
+		require.NoError(t, err, "previous version at height %d", height)
+	}
+	require.NoError(t, previousApp.Close())
+
+	SetUpgradeHeight(t, test.Name, appGenesis.ChainID, test.Height)
+
+	rpsApp, err := app.NewRPSApp(log.NewNopLogger(), db, nil, true, simtestutil.NewAppOptionsWithFlagHome(home), baseapp.SetChainID(appGenesis.ChainID))
+	require.NoError(t, err)
+	require.Equal(t, test.Height-1, rpsApp.LastBlockHeight())
+
+	_, err = FinalizeAndCommit(rpsApp, test.Height, heightTime(appGenesis.GenesisTime, test.Height), nil)
+	require.NoError(t, err, "upgrade at height %d", test.Height)
+
+	return UpgradeResult{
+		App:     rpsApp,
+		Genesis: appGenesis,
+	}
+}
+
+// FinalizeAndCommit executes and commits the block of the given height and
+// txs on the upgraded RPSApp.
+func (r UpgradeResult) FinalizeAndCommit(height int64, txs [][]byte) (*abci.ResponseFinalizeBlock, error) {
+	return FinalizeAndCommit(r.App, height, heightTime(r.Genesis.GenesisTime, height), txs)
+}
+
+// newPreviousApp builds the app of the previous version from its app.yaml.
+func newPreviousApp(t *testing.T, appConfig []byte, db dbm.DB, home, chainID string) *runtime.App {
+	t.Helper()
+
+	var appBuilder *runtime.AppBuilder
+	require.NoError(t, depinject.Inject(
+		depinject.Configs(
+			appconfig.LoadYAML(appConfig),
+			depinject.Supply(
+				log.NewNopLogger(),
+				simtestutil.NewAppOptionsWithFlagHome(home),
+				map[string]module.AppModuleBasic{
+					genutiltypes.ModuleName: genutil.NewAppModuleBasic(genutiltypes.DefaultMessageValidator),
+				},
+			),
+		),
+		&appBuilder,
+	))
+
+	previousApp := appBuilder.Build(db, nil, baseapp.SetChainID(chainID))
+	require.NoError(t, previousApp.Load(true))
+
+	return previousApp
+}
+
+// SetUpgradeHeight sets the compiled-in height of the named upgrade on
+// chainID for the duration of the test.
+func SetUpgradeHeight(t *testing.T, name, chainID string, height int64) {
+	t.Helper()
+
+	for _, upgrade := range app.Upgrades {
+		if upgrade.Name != name {
+			continue
+		}
+		require.NotNil(t, upgrade.Heights, "upgrade %s has no compiled-in heights", name)
+
+		previous, ok := upgrade.Heights[chainID]
+		upgrade.Heights[chainID] = height
+		t.Cleanup(func() {
+			if ok {
+				upgrade.Heights[chainID] = previous
+			} else {
+				delete(upgrade.Heights, chainID)
+			}
+		})
+
+		return
+	}
+
+	t.Fatalf("upgrade %s not registered", name)
+}
+
+// heightTime returns the time of the block at height, the blocks being
+// blockInterval apart from the genesis time.
+func heightTime(genesisTime time.Time, height int64) time.Time {
+	return genesisTime.Add(time.Duration(height) * blockInterval)
+}
+
+// ABCIApp is the subset of the ABCI methods executing blocks.
+type ABCIApp interface {
+	FinalizeBlock(*abci.RequestFinalizeBlock) (*abci.ResponseFinalizeBlock, error)
+	Commit() (*abci.ResponseCommit, error)
+}
+
+// FinalizeAndCommit executes and commits the block of the given height, time
+// and txs.
+func FinalizeAndCommit(abciApp ABCIApp, height int64, blockTime time.Time, txs [][]byte) (*abci.ResponseFinalizeBlock, error) {
+	res, err := abciApp.FinalizeBlock(&abci.RequestFinalizeBlock{
+		Height: height,
+		Time:   blockTime,
+		Txs:    txs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := abciApp.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ExportAndReimport exports the state of rpsApp, as rpsd export does, and
+// initializes a new RPSApp from it, executing its first block.
+func ExportAndReimport(t *testing.T, rpsApp *app.RPSApp, chainID string) *app.RPSApp {
+	t.Helper()
+
+	exported, err := rpsApp.ExportAppStateAndValidators(false, nil, nil)
+	require.NoError(t, err)
+
+	var genesisState map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(exported.AppState, &genesisState))
+	require.NoError(t, module.NewBasicManagerFromManager(rpsApp.ModuleManager, nil).
+		ValidateGenesis(rpsApp.AppCodec(), rpsApp.TxConfig(), genesisState))
+
+	reimported, err := app.NewRPSApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, simtestutil.NewAppOptionsWithFlagHome(t.TempDir()), baseapp.SetChainID(chainID))
+	require.NoError(t, err)
+
+	genesisTime := time.Now().UTC()
+	_, err = reimported.InitChain(&abci.RequestInitChain{
+		Time:            genesisTime,
+		ChainId:         chainID,
+		ConsensusParams: &exported.ConsensusParams,
+		AppStateBytes:   exported.AppState,
+		InitialHeight:   exported.Height,
+	})
+	require.NoError(t, err, "reimport")
+
+	_, err = FinalizeAndCommit(reimported, exported.Height, genesisTime.Add(blockInterval), nil)
+	require.NoError(t, err, "first block %d after reimport", exported.Height)
+
+	return reimported
+}
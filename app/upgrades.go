@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/0xlb/rps-chain/app/upgrades"
+	v2 "github.com/0xlb/rps-chain/app/upgrades/v2"
+)
+
+// Upgrades are the software upgrades of the chain, the last one being the
+// pending upgrade.
+var Upgrades = []upgrades.Upgrade{
+	v2.Upgrade,
+}
+
+// setUpgradeHandlers registers the handlers of the Upgrades and the store
+// loader of the upgrade to apply when the node restarts at the block before
+// it: the upgrade of the upgrade info written to disk by x/upgrade when
+// halting at a plan, or else the upgrade at a compiled-in height of the chain.
+// It also sets the PreBlocker applying the latter.
+func (app *RPSApp) setUpgradeHandlers() error {
+	keepers := upgrades.AppKeepers{
+		MintKeeper:    app.MintKeeper,
+		GovKeeper:     app.GovKeeper,
+		UpgradeKeeper: app.UpgradeKeeper,
+		CrisisKeeper:  app.CrisisKeeper,
+	}
+
+	for _, upgrade := range Upgrades {
+		app.UpgradeKeeper.SetUpgradeHandler(
+			upgrade.Name,
+			upgrade.CreateUpgradeHandler(app.ModuleManager, app.Configurator(), keepers),
+		)
+	}
+
+	app.SetPreBlocker(app.preBlocker)
+
+	upgradeInfo, err := app.UpgradeKeeper.ReadUpgradeInfoFromDisk()
+	if err != nil {
+		return fmt.Errorf("failed to read upgrade info from disk: %w", err)
+	}
+
+	if upgradeInfo.Name != "" && !app.UpgradeKeeper.IsSkipHeight(upgradeInfo.Height) {
+		for _, upgrade := range Upgrades {
+			if upgrade.Name == upgradeInfo.Name {
+				storeUpgrades := upgrade.StoreUpgrades
+				app.SetStoreLoader(upgradetypes.UpgradeStoreLoader(upgradeInfo.Height, &storeUpgrades))
+				return nil
+			}
+		}
+	}
+
+	// the store loader only upgrades the stores at the block before the height
+	for _, upgrade := range Upgrades {
+		if height, ok := upgrade.Heights[app.ChainID()]; ok && !app.UpgradeKeeper.IsSkipHeight(height) {
+			storeUpgrades := upgrade.StoreUpgrades
+			app.SetStoreLoader(upgradetypes.UpgradeStoreLoader(height, &storeUpgrades))
+		}
+	}
+
+	return nil
+}
+
+// preBlocker applies the upgrade at a compiled-in height of the chain, if
+// any, then runs the PreBlockers of the modules. It fails the blocks before a
+// compiled-in height which isn't applied yet: they belong to the previous
+// version, as with the x/upgrade guard of a binary updated before its plan.
+func (app *RPSApp) preBlocker(ctx sdk.Context, req *abci.RequestFinalizeBlock) (*sdk.ResponsePreBlock, error) {
+	applied, err := app.applyCompiledInUpgrade(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := app.App.PreBlocker(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// the upgrade may change the consensus params, as x/upgrade assumes
+	res.ConsensusParamsChanged = res.ConsensusParamsChanged || applied
+
+	return res, nil
+}
+
+// applyCompiledInUpgrade applies the upgrade whose compiled-in height on the
+// chain is the block height, as x/upgrade applies a plan: it reports whether
+// an upgrade was applied. It returns an error for a block before the
+// compiled-in height of an upgrade which isn't done.
+func (app *RPSApp) applyCompiledInUpgrade(ctx sdk.Context) (bool, error) {
+	for _, upgrade := range Upgrades {
+		height, ok := upgrade.Heights[ctx.ChainID()]
+		if !ok || ctx.BlockHeight() > height {
+			continue
+		}
+
+		if ctx.BlockHeight() < height {
+			doneHeight, err := app.UpgradeKeeper.GetDoneHeight(ctx, upgrade.Name)
+			if err != nil {
+				return false, err
+			}
+
+			if doneHeight == 0 {
+				return false, fmt.Errorf("binary updated before upgrade %s at height %d: the previous version must execute block %d", upgrade.Name, height, ctx.BlockHeight())
+			}
+
+			continue
+		}
+
+		if app.UpgradeKeeper.IsSkipHeight(height) {
+			ctx.Logger().Info("skipped upgrade", "name", upgrade.Name, "height", height)
+			return false, nil
+		}
+
+		ctx.Logger().Info("applying upgrade", "name", upgrade.Name, "height", height)
+		if err := app.UpgradeKeeper.ApplyUpgrade(ctx, upgradetypes.Plan{Name: upgrade.Name, Height: height}); err != nil {
+			return false, fmt.Errorf("failed to apply upgrade %s: %w", upgrade.Name, err)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
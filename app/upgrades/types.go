@@ -0,0 +1,40 @@
+package upgrades
+
+import (
+	storetypes "cosmossdk.io/store/types"
+	upgradekeeper "cosmossdk.io/x/upgrade/keeper"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+
+	"github.com/cosmos/cosmos-sdk/types/module"
+	crisiskeeper "github.com/cosmos/cosmos-sdk/x/crisis/keeper"
+	govkeeper "github.com/cosmos/cosmos-sdk/x/gov/keeper"
+	mintkeeper "github.com/cosmos/cosmos-sdk/x/mint/keeper"
+)
+
+// AppKeepers are the keepers the upgrade handlers can use.
+type AppKeepers struct {
+	MintKeeper    mintkeeper.Keeper
+	GovKeeper     *govkeeper.Keeper
+	UpgradeKeeper *upgradekeeper.Keeper
+	CrisisKeeper  *crisiskeeper.Keeper
+}
+
+// Upgrade is a software upgrade of the chain, applied at the height of the
+// upgrade plan of the same name, or at its compiled-in height on the chains
+// listed in Heights.
+type Upgrade struct {
+	// Name is the name of the upgrade plan.
+	Name string
+
+	// Heights are the compiled-in upgrade heights, by chain ID, of an upgrade
+	// whose plan can't be scheduled by a tx, e.g. from a version without
+	// x/upgrade. The nodes halt at the block before it with --halt-height.
+	Heights map[string]int64
+
+	// CreateUpgradeHandler returns the handler of the upgrade plan, running
+	// the migrations of the modules.
+	CreateUpgradeHandler func(*module.Manager, module.Configurator, AppKeepers) upgradetypes.UpgradeHandler
+
+	// StoreUpgrades are the stores added, renamed or deleted by the upgrade.
+	StoreUpgrades storetypes.StoreUpgrades
+}
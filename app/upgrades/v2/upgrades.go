@@ -0,0 +1,128 @@
+package v2
+
+import (
+	"context"
+
+	storetypes "cosmossdk.io/store/types"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	crisistypes "github.com/cosmos/cosmos-sdk/x/crisis/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/cosmos/cosmos-sdk/x/group"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+
+	capabilitytypes "github.com/cosmos/ibc-go/modules/capability/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	ibcexported "github.com/cosmos/ibc-go/v8/modules/core/exported"
+
+	"github.com/0xlb/rps-chain/app/params"
+	"github.com/0xlb/rps-chain/app/upgrades"
+)
+
+// UpgradeName is the name of the upgrade from the first release, which adds
+// the x/mint, x/gov, x/upgrade, x/crisis and x/group modules, and IBC with
+// ICS-20 transfers.
+const UpgradeName = "v2"
+
+// addedModules are the modules added by the upgrade.
+var addedModules = []string{
+	minttypes.ModuleName,
+	govtypes.ModuleName,
+	upgradetypes.ModuleName,
+	crisistypes.ModuleName,
+	group.ModuleName,
+	capabilitytypes.ModuleName,
+	ibcexported.ModuleName,
+	ibctransfertypes.ModuleName,
+}
+
+// Heights are the heights of the upgrade by chain ID. The first release has
+// no x/upgrade to schedule a plan, so the height of each network is compiled
+// in once its operators agree on it.
+var Heights = map[string]int64{}
+
+var Upgrade = upgrades.Upgrade{
+	Name:                 UpgradeName,
+	Heights:              Heights,
+	CreateUpgradeHandler: CreateUpgradeHandler,
+	StoreUpgrades: storetypes.StoreUpgrades{
+		Added: []string{
+			minttypes.StoreKey,
+			govtypes.StoreKey,
+			upgradetypes.StoreKey,
+			crisistypes.StoreKey,
+			group.StoreKey,
+			capabilitytypes.StoreKey,
+			ibcexported.StoreKey,
+			ibctransfertypes.StoreKey,
+		},
+	},
+}
+
+// CreateUpgradeHandler returns the v2 upgrade handler. The first release
+// had no x/upgrade, hence no module version map: its modules are at their
+// current version since the SDK version is unchanged, while the added modules
+// are initialized with their default genesis by the migrations, capability
+// first since they run in the alphabetical order.
+func CreateUpgradeHandler(mm *module.Manager, configurator module.Configurator, keepers upgrades.AppKeepers) upgradetypes.UpgradeHandler {
+	return func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+		if len(fromVM) == 0 {
+			fromVM = mm.GetVersionMap()
+			for _, name := range addedModules {
+				delete(fromVM, name)
+			}
+		}
+
+		versionMap, err := mm.RunMigrations(ctx, configurator, fromVM)
+		if err != nil {
+			return nil, err
+		}
+
+		// the default genesis states of x/mint, x/gov and x/crisis use the sdk
+		// default bond denom
+		mintParams, err := keepers.MintKeeper.Params.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		mintParams.MintDenom = params.DefaultBondDenom
+		if err := keepers.MintKeeper.Params.Set(ctx, mintParams); err != nil {
+			return nil, err
+		}
+
+		govParams, err := keepers.GovKeeper.Params.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		govParams.MinDeposit = withDenom(govParams.MinDeposit)
+		govParams.ExpeditedMinDeposit = withDenom(govParams.ExpeditedMinDeposit)
+		if err := keepers.GovKeeper.Params.Set(ctx, govParams); err != nil {
+			return nil, err
+		}
+
+		constantFee, err := keepers.CrisisKeeper.ConstantFee.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		constantFee.Denom = params.DefaultBondDenom
+		if err := keepers.CrisisKeeper.ConstantFee.Set(ctx, constantFee); err != nil {
+			return nil, err
+		}
+
+		return versionMap, nil
+	}
+}
+
+// withDenom returns the amounts of coins in the bond denom.
+func withDenom(coins sdk.Coins) sdk.Coins {
+	converted := make(sdk.Coins, 0, len(coins))
+	for _, coin := range coins {
+		converted = converted.Add(sdk.NewCoin(params.DefaultBondDenom, coin.Amount))
+	}
+
+	return converted
+}
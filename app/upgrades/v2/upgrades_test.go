@@ -0,0 +1,150 @@
+package v2_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	dbm "github.com/cosmos/cosmos-db"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/log"
+	"cosmossdk.io/math"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app"
+	"github.com/0xlb/rps-chain/app/params"
+	"github.com/0xlb/rps-chain/app/testutil"
+	v2 "github.com/0xlb/rps-chain/app/upgrades/v2"
+)
+
+const upgradeHeight = 5
+
+// the accounts of the fixture genesis: alice is the validator, self-delegating
+// 1000000rps of her 10000000rps with her gentx
+const (
+	alice = "rps1u3hjc5u57wgpaz9tnltwn83p77p5vd4yjyqw5l"
+	bob   = "rps137rr0taremlavmfjcdmmm5pwkpmjhmq0wvgfsv"
+)
+
+func TestUpgrade(t *testing.T) {
+	previousAppConfig, err := os.ReadFile("testdata/app.yaml")
+	require.NoError(t, err)
+
+	res := testutil.RunUpgrade(t, testutil.UpgradeTest{
+		Name:              v2.UpgradeName,
+		PreviousAppConfig: previousAppConfig,
+		GenesisFile:       "testdata/genesis.json",
+		Height:            upgradeHeight,
+	})
+	rpsApp := res.App
+	ctx := rpsApp.NewContext(true)
+
+	// the plan is applied and the module versions are those of the RPSApp
+	doneHeight, err := rpsApp.UpgradeKeeper.GetDoneHeight(ctx, v2.UpgradeName)
+	require.NoError(t, err)
+	require.Equal(t, int64(upgradeHeight), doneHeight)
+
+	versionMap, err := rpsApp.UpgradeKeeper.GetModuleVersionMap(ctx)
+	require.NoError(t, err)
+	require.Equal(t, rpsApp.ModuleManager.GetVersionMap(), versionMap)
+
+	_, err = rpsApp.UpgradeKeeper.GetUpgradePlan(ctx)
+	require.ErrorIs(t, err, upgradetypes.ErrNoUpgradePlanFound)
+
+	// the state of the previous version is preserved
+	aliceAddr := sdk.MustAccAddressFromBech32(alice)
+	require.Equal(t, int64(9_000_000), rpsApp.BankKeeper.GetBalance(ctx, aliceAddr, params.DefaultBondDenom).Amount.Int64())
+	require.Equal(t, int64(1000), rpsApp.BankKeeper.GetBalance(ctx, sdk.MustAccAddressFromBech32(bob), params.DefaultBondDenom).Amount.Int64())
+
+	delegations, err := rpsApp.StakingKeeper.GetAllDelegatorDelegations(ctx, aliceAddr)
+	require.NoError(t, err)
+	require.Len(t, delegations, 1)
+	require.Equal(t, "1000000.000000000000000000", delegations[0].Shares.String())
+
+	validators, err := rpsApp.StakingKeeper.GetBondedValidatorsByPower(ctx)
+	require.NoError(t, err)
+	require.Len(t, validators, 1)
+	require.Equal(t, stakingtypes.Bonded, validators[0].Status)
+
+	// x/mint mints the bond denom from the upgrade block on
+	var mintParams minttypes.QueryParamsResponse
+	query(t, rpsApp, "/cosmos.mint.v1beta1.Query/Params", &minttypes.QueryParamsRequest{}, &mintParams)
+	require.Equal(t, params.DefaultBondDenom, mintParams.Params.MintDenom)
+
+	constantFee, err := rpsApp.CrisisKeeper.ConstantFee.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, params.DefaultBondDenom, constantFee.Denom)
+
+	govParams, err := rpsApp.GovKeeper.Params.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{params.DefaultBondDenom}, sdk.Coins(govParams.MinDeposit).Denoms())
+	require.Equal(t, []string{params.DefaultBondDenom}, sdk.Coins(govParams.ExpeditedMinDeposit).Denoms())
+
+	for _, res := range rpsApp.CheckInvariants(ctx) {
+		require.False(t, res.Broken, "%s: %s", res.Route, res.Message)
+	}
+
+	supply := rpsApp.BankKeeper.GetSupply(ctx, params.DefaultBondDenom).Amount
+	require.True(t, supply.GT(math.NewInt(10_001_000)), "supply %s", supply)
+
+	for height := int64(upgradeHeight + 1); height <= upgradeHeight+3; height++ {
+		_, err := res.FinalizeAndCommit(height, nil)
+		require.NoError(t, err, "height %d", height)
+
+		next := rpsApp.BankKeeper.GetSupply(rpsApp.NewContext(true), params.DefaultBondDenom).Amount
+		require.True(t, next.GT(supply), "supply %s at height %d", next, height)
+		supply = next
+	}
+
+	// the migrated state is exported and re-imported
+	reimported := testutil.ExportAndReimport(t, rpsApp, res.Genesis.ChainID)
+	require.Equal(t, int64(1000), reimported.BankKeeper.GetBalance(reimported.NewContext(true), sdk.MustAccAddressFromBech32(bob), params.DefaultBondDenom).Amount.Int64())
+}
+
+func TestUpgradeBinaryUpdatedEarly(t *testing.T) {
+	appGenesis, err := genutiltypes.AppGenesisFromFile("testdata/genesis.json")
+	require.NoError(t, err)
+
+	testutil.SetUpgradeHeight(t, v2.UpgradeName, appGenesis.ChainID, upgradeHeight)
+
+	// a node syncing from genesis with the upgraded binary
+	rpsApp, err := app.NewRPSApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, simtestutil.NewAppOptionsWithFlagHome(t.TempDir()), baseapp.SetChainID(appGenesis.ChainID))
+	require.NoError(t, err)
+
+	consensusParams := appGenesis.Consensus.Params.ToProto()
+	_, err = rpsApp.InitChain(&abci.RequestInitChain{
+		Time:            appGenesis.GenesisTime,
+		ChainId:         appGenesis.ChainID,
+		ConsensusParams: &consensusParams,
+		AppStateBytes:   appGenesis.AppState,
+		InitialHeight:   appGenesis.InitialHeight,
+	})
+	require.NoError(t, err)
+
+	_, err = testutil.FinalizeAndCommit(rpsApp, 1, appGenesis.GenesisTime, nil)
+	require.ErrorContains(t, err, "binary updated before upgrade v2 at height 5: the previous version must execute block 1")
+}
+
+// query runs the gRPC query at path through ABCI, as the node clients do.
+func query(t *testing.T, rpsApp *app.RPSApp, path string, req, res gogoproto.Message) {
+	t.Helper()
+
+	bz, err := gogoproto.Marshal(req)
+	require.NoError(t, err)
+
+	abciRes, err := rpsApp.Query(context.Background(), &abci.RequestQuery{Path: path, Data: bz})
+	require.NoError(t, err)
+	require.Zero(t, abciRes.Code, abciRes.Log)
+
+	require.NoError(t, gogoproto.Unmarshal(abciRes.Value, res))
+}
@@ -23,6 +23,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	"github.com/cosmos/cosmos-sdk/x/crisis"
 	genutilcli "github.com/cosmos/cosmos-sdk/x/genutil/client/cli"
 
 	"github.com/0xlb/rps-chain/app"
@@ -32,15 +33,19 @@ func initRootCmd(rootCmd *cobra.Command, txConfig client.TxConfig, basicManager
 	cfg := sdk.GetConfig()
 	cfg.Seal()
 
+	debugCmd := debug.Cmd()
+	debugCmd.AddCommand(CheckInvariantsCmd())
+
 	rootCmd.AddCommand(
 		genutilcli.InitCmd(basicManager, app.DefaultNodeHome),
-		debug.Cmd(),
+		DevnetCmd(basicManager, app.DefaultNodeHome),
+		debugCmd,
 		confixcmd.ConfigCommand(),
 		pruning.Cmd(newApp, app.DefaultNodeHome),
 		snapshot.Cmd(newApp),
 	)
 
-	server.AddCommands(rootCmd, app.DefaultNodeHome, newApp, appExport, func(startCmd *cobra.Command) {})
+	server.AddCommands(rootCmd, app.DefaultNodeHome, newApp, appExport, addModuleInitFlags)
 
 	// add keybase, auxiliary RPC, query, genesis, and tx child commands
 	rootCmd.AddCommand(
@@ -49,9 +54,14 @@ func initRootCmd(rootCmd *cobra.Command, txConfig client.TxConfig, basicManager
 		queryCommand(),
 		txCommand(),
 		keys.Commands(),
+		RPSCmd(),
 	)
 }
 
+func addModuleInitFlags(startCmd *cobra.Command) {
+	crisis.AddModuleInitFlags(startCmd)
+}
+
 func queryCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                        "query",
@@ -69,6 +79,7 @@ func queryCommand() *cobra.Command {
 		server.QueryBlocksCmd(),
 		authcmd.QueryTxCmd(),
 		server.QueryBlockResultsCmd(),
+		QueryHalvingCmd(),
 	)
 
 	return cmd
@@ -141,20 +152,18 @@ func appExport(
 	viperAppOpts.Set(server.FlagInvCheckPeriod, 1)
 	appOpts = viperAppOpts
 
-	if height != -1 {
-		rpsApp, err = app.NewRPSApp(logger, db, traceStore, false, appOpts)
-		if err != nil {
-			return servertypes.ExportedApp{}, err
-		}
+	rpsApp, err = app.NewRPSApp(logger, db, traceStore, height == -1, appOpts)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+	// the export command doesn't close the database: closing the app closes
+	// it along with the indexer one, the exported state being a copy
+	defer rpsApp.Close()
 
+	if height != -1 {
 		if err := rpsApp.LoadHeight(height); err != nil {
 			return servertypes.ExportedApp{}, err
 		}
-	} else {
-		rpsApp, err = app.NewRPSApp(logger, db, traceStore, true, appOpts)
-		if err != nil {
-			return servertypes.ExportedApp{}, err
-		}
 	}
 
 	return rpsApp.ExportAppStateAndValidators(forZeroHeight, jailAllowedAddrs, modulesToExport)
@@ -0,0 +1,784 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/cosmos/go-bip39"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"cosmossdk.io/math"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	clientconfig "github.com/cosmos/cosmos-sdk/client/config"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/version"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	"github.com/0xlb/rps-chain/app"
+)
+
+const (
+	flagDevnetConfig = "config"
+	flagDevnetForce  = "force"
+
+	defaultDevnetKeyringBackend = keyring.BackendTest
+	defaultDevnetCommissionRate = "0.1"
+	defaultDevnetIP             = "127.0.0.1"
+
+	defaultDevnetP2PPort        = 26656
+	defaultDevnetRPCPort        = 26657
+	defaultDevnetPrometheusPort = 26660
+	defaultDevnetGRPCPort       = 9090
+	defaultDevnetAPIPort        = 1317
+	// devnetPortStride separates the ports of the validators sharing an IP.
+	devnetPortStride = 10
+)
+
+// devnetConfig is the declarative description of a development network read
+// by `rpsd devnet init`.
+type devnetConfig struct {
+	ChainID        string            `json:"chain_id"`
+	KeyringBackend string            `json:"keyring_backend"`
+	Accounts       []devnetAccount   `json:"accounts"`
+	Validators     []devnetValidator `json:"validators"`
+	// Genesis holds per module overrides which are deep merged into the
+	// default genesis state, e.g. staking.params.unbonding_time.
+	Genesis map[string]json.RawMessage `json:"genesis"`
+}
+
+type devnetAccount struct {
+	Name string `json:"name"`
+	// Mnemonic is optional, a new one is generated when left empty.
+	Mnemonic string `json:"mnemonic"`
+	Coins    string `json:"coins"`
+}
+
+type devnetValidator struct {
+	Account        string `json:"account"`
+	Moniker        string `json:"moniker"`
+	Stake          string `json:"stake"`
+	CommissionRate string `json:"commission_rate"`
+	IP             string `json:"ip"`
+	// Home is the node home of the validator. It defaults to --home for the
+	// first validator and is required for every other one.
+	Home string `json:"home"`
+}
+
+type devnetAccountInfo struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Mnemonic string `json:"mnemonic,omitempty"`
+}
+
+type devnetValidatorInfo struct {
+	Moniker string      `json:"moniker"`
+	NodeID  string      `json:"node_id"`
+	Home    string      `json:"home"`
+	Ports   devnetPorts `json:"ports"`
+}
+
+// devnetPorts are the ports a devnet node listens on.
+type devnetPorts struct {
+	P2P        int `json:"p2p"`
+	RPC        int `json:"rpc"`
+	GRPC       int `json:"grpc"`
+	API        int `json:"api"`
+	Prometheus int `json:"prometheus"`
+}
+
+type devnetInfo struct {
+	ChainID    string                `json:"chain_id"`
+	Accounts   []devnetAccountInfo   `json:"accounts"`
+	Validators []devnetValidatorInfo `json:"validators"`
+}
+
+// DevnetCmd returns the devnet command, used to bootstrap local development networks.
+func DevnetCmd(basicManager module.BasicManager, defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        "devnet",
+		Short:                      "Local development network subcommands",
+		DisableFlagParsing:         false,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	cmd.AddCommand(devnetInitCmd(basicManager, defaultNodeHome))
+
+	return cmd
+}
+
+func devnetInitCmd(basicManager module.BasicManager, defaultNodeHome string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize the node homes and genesis of a development network from a YAML file",
+		Long: `Initialize the node homes and genesis of a development network from a YAML file.
+
+The file declares the chain-id, the accounts to create in the keyring together
+with their genesis balances, the validators and optional module genesis
+overrides. The resulting genesis is validated before being written. An
+already initialized node home is never overwritten unless --force is given.`,
+		Example: fmt.Sprintf("%s devnet init --config devnet.yaml", version.AppName),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			configPath, _ := cmd.Flags().GetString(flagDevnetConfig)
+			force, _ := cmd.Flags().GetBool(flagDevnetForce)
+
+			cfg, err := readDevnetConfig(configPath, clientCtx.HomeDir)
+			if err != nil {
+				return err
+			}
+
+			info, err := initDevnet(clientCtx, basicManager, cfg, force)
+			if err != nil {
+				return err
+			}
+
+			out, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			return clientCtx.PrintRaw(out)
+		},
+	}
+
+	cmd.Flags().String(flags.FlagHome, defaultNodeHome, "The node home directory of the first validator")
+	cmd.Flags().String(flagDevnetConfig, "", "Path to the devnet YAML configuration file")
+	cmd.Flags().Bool(flagDevnetForce, false, "Remove and recreate node homes that are already initialized")
+	_ = cmd.MarkFlagRequired(flagDevnetConfig)
+
+	return cmd
+}
+
+// readDevnetConfig reads the devnet configuration at path, fills in the
+// defaults and validates it.
+func readDevnetConfig(path, defaultHome string) (devnetConfig, error) {
+	var cfg devnetConfig
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read devnet config: %w", err)
+	}
+
+	if err := yaml.UnmarshalStrict(bz, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse devnet config %s: %w", path, err)
+	}
+
+	if cfg.KeyringBackend == "" {
+		cfg.KeyringBackend = defaultDevnetKeyringBackend
+	}
+
+	for i := range cfg.Validators {
+		val := &cfg.Validators[i]
+		if val.Moniker == "" {
+			val.Moniker = val.Account
+		}
+		if val.CommissionRate == "" {
+			val.CommissionRate = defaultDevnetCommissionRate
+		}
+		if val.IP == "" {
+			val.IP = defaultDevnetIP
+		}
+		if i == 0 && val.Home == "" {
+			val.Home = defaultHome
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate performs a stateless validation of the devnet configuration.
+func (cfg devnetConfig) Validate() error {
+	if cfg.ChainID == "" {
+		return errors.New("chain_id cannot be empty")
+	}
+
+	if len(cfg.Accounts) == 0 {
+		return errors.New("at least one account is required")
+	}
+
+	if len(cfg.Validators) == 0 {
+		return errors.New("at least one validator is required")
+	}
+
+	balances := make(map[string]sdk.Coins, len(cfg.Accounts))
+	for _, acc := range cfg.Accounts {
+		if acc.Name == "" {
+			return errors.New("account name cannot be empty")
+		}
+
+		if _, ok := balances[acc.Name]; ok {
+			return fmt.Errorf("duplicate account %q", acc.Name)
+		}
+
+		if acc.Mnemonic != "" && !bip39.IsMnemonicValid(acc.Mnemonic) {
+			return fmt.Errorf("account %q: invalid mnemonic", acc.Name)
+		}
+
+		coins, err := sdk.ParseCoinsNormalized(acc.Coins)
+		if err != nil {
+			return fmt.Errorf("account %q: invalid coins: %w", acc.Name, err)
+		}
+
+		balances[acc.Name] = coins
+	}
+
+	monikers := make(map[string]bool, len(cfg.Validators))
+	homes := make(map[string]bool, len(cfg.Validators))
+	for i, val := range cfg.Validators {
+		coins, ok := balances[val.Account]
+		if !ok {
+			return fmt.Errorf("validator %d: unknown account %q", i, val.Account)
+		}
+
+		if monikers[val.Moniker] {
+			return fmt.Errorf("validator %d: duplicate moniker %q", i, val.Moniker)
+		}
+		monikers[val.Moniker] = true
+
+		if val.Home == "" {
+			return fmt.Errorf("validator %q: home is required for all but the first validator", val.Moniker)
+		}
+
+		home := filepath.Clean(val.Home)
+		if homes[home] {
+			return fmt.Errorf("validator %q: home %s is used by another validator", val.Moniker, val.Home)
+		}
+		homes[home] = true
+
+		stake, err := sdk.ParseCoinNormalized(val.Stake)
+		if err != nil {
+			return fmt.Errorf("validator %q: invalid stake: %w", val.Moniker, err)
+		}
+
+		if !stake.IsPositive() {
+			return fmt.Errorf("validator %q: stake must be positive", val.Moniker)
+		}
+
+		if coins.AmountOf(stake.Denom).LT(stake.Amount) {
+			return fmt.Errorf("validator %q: stake %s exceeds the balance of account %q", val.Moniker, stake, val.Account)
+		}
+
+		rate, err := math.LegacyNewDecFromStr(val.CommissionRate)
+		if err != nil {
+			return fmt.Errorf("validator %q: invalid commission rate: %w", val.Moniker, err)
+		}
+
+		if rate.IsNegative() || rate.GT(math.LegacyOneDec()) {
+			return fmt.Errorf("validator %q: commission rate must be between 0 and 1", val.Moniker)
+		}
+	}
+
+	return nil
+}
+
+// initDevnet writes the keyring, node homes and genesis described by cfg.
+// The genesis is built and validated in memory, first with the account
+// addresses known without the keyring so that --force removes nothing for an
+// invalid genesis, then with the keys, so that nothing but the keys, the node
+// keys and the gentxs is written for an invalid devnet.
+func initDevnet(clientCtx client.Context, basicManager module.BasicManager, cfg devnetConfig, force bool) (devnetInfo, error) {
+	info := devnetInfo{ChainID: cfg.ChainID}
+
+	draftAddresses, err := devnetDraftAddresses(cfg)
+	if err != nil {
+		return info, err
+	}
+
+	if _, err := devnetGenesis(clientCtx, basicManager, cfg, draftAddresses); err != nil {
+		return info, err
+	}
+
+	var homesToRemove []string
+	for _, val := range cfg.Validators {
+		if !nodeHomeInitialized(val.Home) {
+			continue
+		}
+
+		if !force {
+			return info, fmt.Errorf("node home %s is already initialized, use --%s to overwrite it", val.Home, flagDevnetForce)
+		}
+
+		homesToRemove = append(homesToRemove, val.Home)
+	}
+
+	for _, home := range homesToRemove {
+		if err := os.RemoveAll(home); err != nil {
+			return info, fmt.Errorf("failed to remove node home %s: %w", home, err)
+		}
+	}
+
+	firstHome := cfg.Validators[0].Home
+	kb, err := keyring.New(sdk.KeyringServiceName(), cfg.KeyringBackend, firstHome, clientCtx.Input, clientCtx.Codec)
+	if err != nil {
+		return info, err
+	}
+
+	addresses := make(map[string]sdk.AccAddress, len(cfg.Accounts))
+	for _, acc := range cfg.Accounts {
+		accInfo, err := createDevnetAccount(kb, acc, force)
+		if err != nil {
+			return info, err
+		}
+
+		addresses[acc.Name], err = sdk.AccAddressFromBech32(accInfo.Address)
+		if err != nil {
+			return info, err
+		}
+
+		info.Accounts = append(info.Accounts, accInfo)
+	}
+
+	// build the genesis with the accounts and the module overrides
+	appGenesis, err := devnetGenesis(clientCtx, basicManager, cfg, addresses)
+	if err != nil {
+		return info, err
+	}
+
+	// initialize the node keys of every node home
+	nodeConfigs := make([]*cmtcfg.Config, len(cfg.Validators))
+	valPubKeys := make([]cryptotypes.PubKey, len(cfg.Validators))
+	for i, val := range cfg.Validators {
+		ports := devnetValidatorPorts(cfg.Validators, i)
+
+		nodeConfig := initCometBFTConfig()
+		nodeConfig.SetRoot(val.Home)
+		nodeConfig.Moniker = val.Moniker
+		nodeConfig.P2P.ListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", ports.P2P)
+		nodeConfig.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", ports.RPC)
+		nodeConfig.Instrumentation.PrometheusListenAddr = fmt.Sprintf(":%d", ports.Prometheus)
+		// the peers of a devnet are usually private addresses, several of
+		// them on the same host
+		nodeConfig.P2P.AddrBookStrict = false
+		nodeConfig.P2P.AllowDuplicateIP = true
+
+		if err := os.MkdirAll(filepath.Join(val.Home, "config"), 0o755); err != nil {
+			return info, err
+		}
+
+		nodeID, valPubKey, err := genutil.InitializeNodeValidatorFiles(nodeConfig)
+		if err != nil {
+			return info, fmt.Errorf("failed to initialize node validator files of %q: %w", val.Moniker, err)
+		}
+
+		nodeConfigs[i] = nodeConfig
+		valPubKeys[i] = valPubKey
+		info.Validators = append(info.Validators, devnetValidatorInfo{
+			Moniker: val.Moniker,
+			NodeID:  nodeID,
+			Home:    val.Home,
+			Ports:   ports,
+		})
+	}
+
+	// sign the genesis transactions of all validators
+	txConfig := clientCtx.TxConfig
+	gentxsDir := filepath.Join(firstHome, "config", "gentx")
+	if err := os.MkdirAll(gentxsDir, 0o700); err != nil {
+		return info, err
+	}
+
+	for i, val := range cfg.Validators {
+		nodeID := info.Validators[i].NodeID
+		memo := fmt.Sprintf("%s@%s:%d", nodeID, val.IP, info.Validators[i].Ports.P2P)
+
+		bz, err := signDevnetGentx(kb, txConfig, cfg.ChainID, val, addresses[val.Account], valPubKeys[i], memo)
+		if err != nil {
+			return info, fmt.Errorf("failed to create gentx of %q: %w", val.Moniker, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(gentxsDir, fmt.Sprintf("gentx-%s.json", nodeID)), bz, 0o600); err != nil {
+			return info, err
+		}
+	}
+
+	// collect the genesis transactions, every node peering with the others
+	valAddrCodec := txConfig.SigningContext().ValidatorAddressCodec()
+	var appGenTxs []sdk.Tx
+	for i, nodeConfig := range nodeConfigs {
+		appGenTxs, nodeConfig.P2P.PersistentPeers, err = genutil.CollectTxs(
+			clientCtx.Codec, txConfig.TxJSONDecoder(), nodeConfig.Moniker, gentxsDir, appGenesis,
+			banktypes.GenesisBalancesIterator{}, genutiltypes.DefaultMessageValidator, valAddrCodec,
+		)
+		if err != nil {
+			return info, fmt.Errorf("failed to collect the genesis transactions of %q: %w", cfg.Validators[i].Moniker, err)
+		}
+	}
+
+	genesisState, err := genutiltypes.GenesisStateFromAppGenesis(appGenesis)
+	if err != nil {
+		return info, err
+	}
+
+	genesisState, err = genutil.SetGenTxsInAppGenesisState(clientCtx.Codec, txConfig.TxJSONEncoder(), genesisState, appGenTxs)
+	if err != nil {
+		return info, err
+	}
+
+	if err := basicManager.ValidateGenesis(clientCtx.Codec, txConfig, genesisState); err != nil {
+		return info, fmt.Errorf("invalid devnet genesis: %w", err)
+	}
+
+	appGenesis.AppState, err = json.MarshalIndent(genesisState, "", " ")
+	if err != nil {
+		return info, err
+	}
+
+	// the genesis is valid, write the configuration and genesis of every node home
+	for i, nodeConfig := range nodeConfigs {
+		val, ports := cfg.Validators[i], info.Validators[i].Ports
+
+		cmtcfg.WriteConfigFile(filepath.Join(val.Home, "config", "config.toml"), nodeConfig)
+		writeDevnetAppConfig(val.Home, ports)
+
+		if err := writeDevnetClientConfig(val.Home, cfg.ChainID, cfg.KeyringBackend, ports); err != nil {
+			return info, err
+		}
+
+		if err := genutil.ExportGenesisFile(appGenesis, nodeConfig.GenesisFile()); err != nil {
+			return info, fmt.Errorf("failed to export genesis file: %w", err)
+		}
+	}
+
+	return info, nil
+}
+
+// devnetValidatorPorts returns the ports of the i-th validator: the default
+// ports shifted by devnetPortStride for every previous validator sharing its
+// IP, so that the nodes of a single host don't collide.
+func devnetValidatorPorts(validators []devnetValidator, i int) devnetPorts {
+	shift := 0
+	for _, val := range validators[:i] {
+		if val.IP == validators[i].IP {
+			shift += devnetPortStride
+		}
+	}
+
+	return devnetPorts{
+		P2P:        defaultDevnetP2PPort + shift,
+		RPC:        defaultDevnetRPCPort + shift,
+		GRPC:       defaultDevnetGRPCPort + shift,
+		API:        defaultDevnetAPIPort + shift,
+		Prometheus: defaultDevnetPrometheusPort + shift,
+	}
+}
+
+// nodeHomeInitialized reports whether home already holds a genesis or a
+// validator key and signing state. The configuration files alone don't count
+// as they are written by the root command before any subcommand runs.
+func nodeHomeInitialized(home string) bool {
+	for _, path := range []string{
+		filepath.Join(home, "config", "genesis.json"),
+		filepath.Join(home, "config", "priv_validator_key.json"),
+		filepath.Join(home, "data", "priv_validator_state.json"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func createDevnetAccount(kb keyring.Keyring, acc devnetAccount, force bool) (devnetAccountInfo, error) {
+	info := devnetAccountInfo{Name: acc.Name, Mnemonic: acc.Mnemonic}
+
+	// keys of keyring backends living outside of the node home survive its removal
+	if _, err := kb.Key(acc.Name); err == nil {
+		if !force {
+			return info, fmt.Errorf("key %q already exists, use --%s to overwrite it", acc.Name, flagDevnetForce)
+		}
+
+		if err := kb.Delete(acc.Name); err != nil {
+			return info, err
+		}
+	}
+
+	var (
+		record *keyring.Record
+		err    error
+	)
+	if acc.Mnemonic == "" {
+		record, info.Mnemonic, err = kb.NewMnemonic(acc.Name, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+	} else {
+		record, err = kb.NewAccount(acc.Name, acc.Mnemonic, keyring.DefaultBIP39Passphrase, sdk.FullFundraiserPath, hd.Secp256k1)
+	}
+	if err != nil {
+		return info, fmt.Errorf("failed to create key %q: %w", acc.Name, err)
+	}
+
+	addr, err := record.GetAddress()
+	if err != nil {
+		return info, err
+	}
+
+	info.Address = addr.String()
+	return info, nil
+}
+
+// devnetGenesis returns the default genesis extended with the devnet
+// accounts and module overrides. The resulting state is validated.
+func devnetGenesis(
+	clientCtx client.Context,
+	basicManager module.BasicManager,
+	cfg devnetConfig,
+	addresses map[string]sdk.AccAddress,
+) (*genutiltypes.AppGenesis, error) {
+	cdc := clientCtx.Codec
+	genesisState := basicManager.DefaultGenesis(cdc)
+
+	authGenState := authtypes.GetGenesisStateFromAppState(cdc, genesisState)
+	bankGenState := banktypes.GetGenesisStateFromAppState(cdc, genesisState)
+
+	accounts, err := authtypes.UnpackAccounts(authGenState.Accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, acc := range cfg.Accounts {
+		coins, err := sdk.ParseCoinsNormalized(acc.Coins)
+		if err != nil {
+			return nil, fmt.Errorf("account %q: invalid coins: %w", acc.Name, err)
+		}
+
+		addr := addresses[acc.Name]
+		accounts = append(accounts, authtypes.NewBaseAccount(addr, nil, 0, 0))
+		bankGenState.Balances = append(bankGenState.Balances, banktypes.Balance{Address: addr.String(), Coins: coins})
+		bankGenState.Supply = bankGenState.Supply.Add(coins...)
+	}
+
+	authGenState.Accounts, err = authtypes.PackAccounts(authtypes.SanitizeGenesisAccounts(accounts))
+	if err != nil {
+		return nil, err
+	}
+	bankGenState.Balances = banktypes.SanitizeGenesisBalances(bankGenState.Balances)
+
+	genesisState[authtypes.ModuleName] = cdc.MustMarshalJSON(&authGenState)
+	genesisState[banktypes.ModuleName] = cdc.MustMarshalJSON(bankGenState)
+
+	// the rps denom comes first so that the devnet overrides win
+	if err := app.SetDenomGenesis(cdc, genesisState); err != nil {
+		return nil, err
+	}
+
+	for moduleName, override := range cfg.Genesis {
+		moduleState, ok := genesisState[moduleName]
+		if !ok {
+			return nil, fmt.Errorf("genesis override of unknown module %q", moduleName)
+		}
+
+		genesisState[moduleName], err = mergeJSON(moduleState, override)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply the genesis override of module %q: %w", moduleName, err)
+		}
+	}
+
+	if err := basicManager.ValidateGenesis(cdc, clientCtx.TxConfig, genesisState); err != nil {
+		return nil, fmt.Errorf("invalid genesis: %w", err)
+	}
+
+	appState, err := json.MarshalIndent(genesisState, "", " ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal genesis state: %w", err)
+	}
+
+	return genutiltypes.NewAppGenesisWithVersion(cfg.ChainID, appState), nil
+}
+
+// devnetDraftAddresses returns the account addresses known before any key is
+// created: the ones derived from the configured mnemonics, and a placeholder
+// for the accounts whose mnemonic is generated.
+func devnetDraftAddresses(cfg devnetConfig) (map[string]sdk.AccAddress, error) {
+	addresses := make(map[string]sdk.AccAddress, len(cfg.Accounts))
+	for _, acc := range cfg.Accounts {
+		if acc.Mnemonic == "" {
+			addresses[acc.Name] = sdk.AccAddress(address.Hash("devnet", []byte(acc.Name)))
+			continue
+		}
+
+		derivedPriv, err := hd.Secp256k1.Derive()(acc.Mnemonic, keyring.DefaultBIP39Passphrase, sdk.FullFundraiserPath)
+		if err != nil {
+			return nil, fmt.Errorf("account %q: failed to derive key: %w", acc.Name, err)
+		}
+
+		addresses[acc.Name] = sdk.AccAddress(hd.Secp256k1.Generate()(derivedPriv).PubKey().Address())
+	}
+
+	return addresses, nil
+}
+
+// mergeJSON deep merges override into base. JSON objects are merged key by
+// key while any other value of override replaces the one of base.
+func mergeJSON(base, override json.RawMessage) (json.RawMessage, error) {
+	var baseValue, overrideValue interface{}
+	if err := unmarshalJSONNumbers(base, &baseValue); err != nil {
+		return nil, err
+	}
+
+	if err := unmarshalJSONNumbers(override, &overrideValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValues(baseValue, overrideValue))
+}
+
+func mergeValues(base, override interface{}) interface{} {
+	baseMap, ok := base.(map[string]interface{})
+	if !ok {
+		return override
+	}
+
+	overrideMap, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+
+	for k, v := range overrideMap {
+		baseMap[k] = mergeValues(baseMap[k], v)
+	}
+
+	return baseMap
+}
+
+// unmarshalJSONNumbers unmarshals bz keeping numbers as json.Number so that
+// large integers don't lose precision.
+func unmarshalJSONNumbers(bz []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func signDevnetGentx(
+	kb keyring.Keyring,
+	txConfig client.TxConfig,
+	chainID string,
+	val devnetValidator,
+	addr sdk.AccAddress,
+	valPubKey cryptotypes.PubKey,
+	memo string,
+) ([]byte, error) {
+	stake, err := sdk.ParseCoinNormalized(val.Stake)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := math.LegacyNewDecFromStr(val.CommissionRate)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRate := math.LegacyMaxDec(rate, math.LegacyNewDecWithPrec(2, 1))
+	msg, err := stakingtypes.NewMsgCreateValidator(
+		sdk.ValAddress(addr).String(),
+		valPubKey,
+		stake,
+		stakingtypes.NewDescription(val.Moniker, "", "", "", ""),
+		stakingtypes.NewCommissionRates(rate, maxRate, math.LegacyNewDecWithPrec(1, 2)),
+		math.OneInt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := msg.Validate(txConfig.SigningContext().ValidatorAddressCodec()); err != nil {
+		return nil, err
+	}
+
+	txBuilder := txConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(msg); err != nil {
+		return nil, err
+	}
+	txBuilder.SetMemo(memo)
+	txBuilder.SetGasLimit(flags.DefaultGasLimit)
+
+	txFactory := tx.Factory{}.
+		WithKeybase(kb).
+		WithTxConfig(txConfig).
+		WithChainID(chainID).
+		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT)
+
+	if err := tx.Sign(context.Background(), txFactory, val.Account, txBuilder, true); err != nil {
+		return nil, err
+	}
+
+	return txConfig.TxJSONEncoder()(txBuilder.GetTx())
+}
+
+// writeDevnetAppConfig writes the app.toml of a node home with the rpsd
+// defaults and the gRPC and API ports of the node.
+func writeDevnetAppConfig(home string, ports devnetPorts) {
+	customAppTemplate, customAppConfig := initAppConfig()
+
+	appConfig := customAppConfig.(app.CustomAppConfig)
+	appConfig.GRPC.Address = fmt.Sprintf("localhost:%d", ports.GRPC)
+	appConfig.API.Address = fmt.Sprintf("tcp://localhost:%d", ports.API)
+
+	serverconfig.SetConfigTemplate(customAppTemplate)
+	serverconfig.WriteConfigFile(filepath.Join(home, "config", "app.toml"), appConfig)
+}
+
+// devnetClientConfigTemplate mirrors the client.toml written by the SDK.
+const devnetClientConfigTemplate = `# This is a TOML config file.
+# For more information, see https://github.com/toml-lang/toml
+
+###############################################################################
+###                           Client Configuration                            ###
+###############################################################################
+
+# The network chain ID
+chain-id = "{{ .ChainID }}"
+# The keyring's backend, where the keys are stored (os|file|kwallet|pass|test|memory)
+keyring-backend = "{{ .KeyringBackend }}"
+# CLI output format (text|json)
+output = "{{ .Output }}"
+# <host>:<port> to CometBFT RPC interface for this chain
+node = "{{ .Node }}"
+# Transaction broadcasting mode (sync|async)
+broadcast-mode = "{{ .BroadcastMode }}"
+`
+
+// writeDevnetClientConfig writes the client.toml of a node home so that the
+// CLI targets the devnet chain-id, keyring and node without further
+// configuration.
+func writeDevnetClientConfig(home, chainID, keyringBackend string, ports devnetPorts) error {
+	conf := clientconfig.DefaultConfig()
+	conf.SetChainID(chainID)
+	conf.SetKeyringBackend(keyringBackend)
+	conf.SetNode(fmt.Sprintf("tcp://localhost:%d", ports.RPC))
+
+	tmpl, err := template.New("clientConfigFileTemplate").Parse(devnetClientConfigTemplate)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, conf); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(home, "config", "client.toml"), buffer.Bytes(), 0o600)
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	svrcmd "github.com/cosmos/cosmos-sdk/server/cmd"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMergeJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     string
+		override string
+		expected string
+	}{
+		{
+			name:     "nested objects are merged",
+			base:     `{"params":{"unbonding_time":"1814400s","max_validators":100},"validators":[]}`,
+			override: `{"params":{"unbonding_time":"600s"}}`,
+			expected: `{"params":{"unbonding_time":"600s","max_validators":100},"validators":[]}`,
+		},
+		{
+			name:     "new keys are added",
+			base:     `{"params":{}}`,
+			override: `{"params":{"mint_denom":"rps"},"minter":{"inflation":"0"}}`,
+			expected: `{"params":{"mint_denom":"rps"},"minter":{"inflation":"0"}}`,
+		},
+		{
+			name:     "arrays are replaced",
+			base:     `{"denoms":["a","b"]}`,
+			override: `{"denoms":["c"]}`,
+			expected: `{"denoms":["c"]}`,
+		},
+		{
+			name:     "an object replaces a scalar",
+			base:     `{"fee":"1rps"}`,
+			override: `{"fee":{"denom":"rps","amount":"1"}}`,
+			expected: `{"fee":{"denom":"rps","amount":"1"}}`,
+		},
+		{
+			name:     "large integers keep their precision",
+			base:     `{"supply":1}`,
+			override: `{"supply":123456789012345678901234567890}`,
+			expected: `{"supply":123456789012345678901234567890}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := mergeJSON(json.RawMessage(tc.base), json.RawMessage(tc.override))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(merged))
+		})
+	}
+
+	_, err := mergeJSON(json.RawMessage(`{}`), json.RawMessage(`{`))
+	require.Error(t, err)
+}
+
+func TestDevnetConfigValidate(t *testing.T) {
+	validConfig := func() devnetConfig {
+		return devnetConfig{
+			ChainID: "rps-1",
+			Accounts: []devnetAccount{
+				{Name: "alice", Coins: "10000000rps"},
+				{Name: "bob", Coins: "1000rps"},
+			},
+			Validators: []devnetValidator{
+				{Account: "alice", Moniker: "alice", Stake: "1000000rps", CommissionRate: "0.1", Home: "/tmp/alice"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		malleate func(*devnetConfig)
+		errMsg   string
+	}{
+		{"valid", func(*devnetConfig) {}, ""},
+		{"empty chain-id", func(cfg *devnetConfig) { cfg.ChainID = "" }, "chain_id cannot be empty"},
+		{"no account", func(cfg *devnetConfig) { cfg.Accounts = nil }, "at least one account is required"},
+		{"no validator", func(cfg *devnetConfig) { cfg.Validators = nil }, "at least one validator is required"},
+		{"empty account name", func(cfg *devnetConfig) { cfg.Accounts[1].Name = "" }, "account name cannot be empty"},
+		{"duplicate account", func(cfg *devnetConfig) { cfg.Accounts[1].Name = "alice" }, `duplicate account "alice"`},
+		{"invalid mnemonic", func(cfg *devnetConfig) { cfg.Accounts[0].Mnemonic = "not a mnemonic" }, `account "alice": invalid mnemonic`},
+		{"invalid coins", func(cfg *devnetConfig) { cfg.Accounts[0].Coins = "10" }, `account "alice": invalid coins`},
+		{"unknown account", func(cfg *devnetConfig) { cfg.Validators[0].Account = "carol" }, `validator 0: unknown account "carol"`},
+		{
+			"duplicate moniker",
+			func(cfg *devnetConfig) {
+				cfg.Validators = append(cfg.Validators, devnetValidator{Account: "bob", Moniker: "alice", Stake: "1rps", CommissionRate: "0.1", Home: "/tmp/bob"})
+			},
+			`validator 1: duplicate moniker "alice"`,
+		},
+		{"missing home", func(cfg *devnetConfig) { cfg.Validators[0].Home = "" }, "home is required"},
+		{
+			"shared home",
+			func(cfg *devnetConfig) {
+				cfg.Validators = append(cfg.Validators, devnetValidator{Account: "bob", Moniker: "bob", Stake: "1rps", CommissionRate: "0.1", Home: "/tmp/alice/"})
+			},
+			"is used by another validator",
+		},
+		{"invalid stake", func(cfg *devnetConfig) { cfg.Validators[0].Stake = "rps" }, "invalid stake"},
+		{"zero stake", func(cfg *devnetConfig) { cfg.Validators[0].Stake = "0rps" }, "stake must be positive"},
+		{"stake above balance", func(cfg *devnetConfig) { cfg.Validators[0].Stake = "10000001rps" }, `exceeds the balance of account "alice"`},
+		{"invalid commission rate", func(cfg *devnetConfig) { cfg.Validators[0].CommissionRate = "ten" }, "invalid commission rate"},
+		{"commission rate above 1", func(cfg *devnetConfig) { cfg.Validators[0].CommissionRate = "1.5" }, "commission rate must be between 0 and 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.malleate(&cfg)
+
+			err := cfg.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestDevnetValidatorPorts(t *testing.T) {
+	validators := []devnetValidator{
+		{IP: "127.0.0.1"},
+		{IP: "10.0.0.2"},
+		{IP: "127.0.0.1"},
+		{IP: "127.0.0.1"},
+	}
+
+	// the first node of every host gets the default ports
+	require.Equal(t, devnetPorts{P2P: 26656, RPC: 26657, GRPC: 9090, API: 1317, Prometheus: 26660}, devnetValidatorPorts(validators, 0))
+	require.Equal(t, devnetPorts{P2P: 26656, RPC: 26657, GRPC: 9090, API: 1317, Prometheus: 26660}, devnetValidatorPorts(validators, 1))
+	require.Equal(t, devnetPorts{P2P: 26666, RPC: 26667, GRPC: 9100, API: 1327, Prometheus: 26670}, devnetValidatorPorts(validators, 2))
+	require.Equal(t, devnetPorts{P2P: 26676, RPC: 26677, GRPC: 9110, API: 1337, Prometheus: 26680}, devnetValidatorPorts(validators, 3))
+}
+
+// executeDevnetInit runs `rpsd devnet init` with the YAML config and flags.
+func executeDevnetInit(t *testing.T, home, config string, flags ...string) error {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "devnet.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0o600))
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs(append([]string{"devnet", "init", "--home", home, "--config", configPath}, flags...))
+	rootCmd.SetOut(new(bytes.Buffer))
+	rootCmd.SetErr(new(bytes.Buffer))
+
+	return svrcmd.Execute(rootCmd, "", home)
+}
+
+func TestDevnetInitForce(t *testing.T) {
+	home0, home1 := t.TempDir(), t.TempDir()
+	config := fmt.Sprintf(`chain_id: rps-1
+accounts:
+  - name: alice
+    coins: 10000000rps
+  - name: bob
+    mnemonic: "%s"
+    coins: 10000000rps
+validators:
+  - account: alice
+    stake: 1000000rps
+  - account: bob
+    stake: 1000000rps
+    home: %s
+`, testMnemonic, home1)
+
+	require.NoError(t, executeDevnetInit(t, home0, config))
+
+	readValidatorKeys := func() [][]byte {
+		var keys [][]byte
+		for _, home := range []string{home0, home1} {
+			bz, err := os.ReadFile(filepath.Join(home, "config", "priv_validator_key.json"))
+			require.NoError(t, err)
+			keys = append(keys, bz)
+		}
+		return keys
+	}
+	validatorKeys := readValidatorKeys()
+
+	// an initialized devnet isn't overwritten without --force
+	require.ErrorContains(t, executeDevnetInit(t, home0, config), "is already initialized")
+	require.Equal(t, validatorKeys, readValidatorKeys())
+
+	// nor with --force if its genesis is invalid
+	testCases := []struct {
+		name    string
+		genesis string
+		errMsg  string
+	}{
+		{"unknown module", "  nft:\n    classes: []\n", `genesis override of unknown module "nft"`},
+		{"override replacing a module genesis", "  staking: 1\n", "invalid genesis"},
+		{"invalid module genesis", "  staking:\n    params:\n      bond_denom: \"\"\n", "invalid genesis"},
+		{"invalid balances", "  bank:\n    balances:\n      - address: rps1invalid\n", "invalid genesis"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorContains(t, executeDevnetInit(t, home0, config+"genesis:\n"+tc.genesis, "--force"), tc.errMsg)
+			require.Equal(t, validatorKeys, readValidatorKeys())
+		})
+	}
+
+	require.NoError(t, executeDevnetInit(t, home0, config, "--force"))
+	require.NotEqual(t, validatorKeys, readValidatorKeys())
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/rpc"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+
+	"github.com/0xlb/rps-chain/app"
+)
+
+type halvingInfo struct {
+	Height             int64  `json:"height"`
+	Era                int64  `json:"era"`
+	BlockReward        string `json:"block_reward"`
+	AnnualProvisions   string `json:"annual_provisions"`
+	NextHalvingHeight  int64  `json:"next_halving_height"`
+	BlocksUntilHalving int64  `json:"blocks_until_halving"`
+}
+
+// QueryHalvingCmd returns the command querying the state of the rps halving
+// schedule. The block reward and annual provisions are those of the x/mint
+// minter and the halvings are derived from the chain height, all read at the
+// same height.
+func QueryHalvingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "halving",
+		Short: "Query the current block reward, annual provisions and next halving height",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			clientCtx, err := client.GetClientQueryContext(cmd)
+			if err != nil {
+				return err
+			}
+
+			height := clientCtx.Height
+			if height == 0 {
+				height, err = rpc.GetChainHeight(clientCtx)
+				if err != nil {
+					return err
+				}
+				clientCtx = clientCtx.WithHeight(height)
+			}
+
+			queryClient := minttypes.NewQueryClient(clientCtx)
+
+			params, err := queryClient.Params(cmd.Context(), &minttypes.QueryParamsRequest{})
+			if err != nil {
+				return err
+			}
+
+			provisions, err := queryClient.AnnualProvisions(cmd.Context(), &minttypes.QueryAnnualProvisionsRequest{})
+			if err != nil {
+				return err
+			}
+
+			minter := minttypes.Minter{AnnualProvisions: provisions.AnnualProvisions}
+			schedule := app.DefaultHalvingSchedule
+			nextHalving := schedule.NextHalvingHeight(height)
+
+			out, err := json.MarshalIndent(halvingInfo{
+				Height:             height,
+				Era:                schedule.Era(height),
+				BlockReward:        minter.BlockProvision(params.Params).String(),
+				AnnualProvisions:   provisions.AnnualProvisions.String() + params.Params.MintDenom,
+				NextHalvingHeight:  nextHalving,
+				BlocksUntilHalving: nextHalving - height,
+			}, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			return clientCtx.PrintRaw(out)
+		},
+	}
+
+	flags.AddQueryFlagsToCmd(cmd)
+
+	return cmd
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/server"
+
+	"github.com/0xlb/rps-chain/app"
+)
+
+const flagHeight = "height"
+
+// CheckInvariantsCmd returns the command checking the invariants of the
+// modules against the state of the node database, at the latest or a given
+// height. The node must be stopped.
+func CheckInvariantsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-invariants",
+		Short: "Check the module invariants against the state of the stopped node",
+		Long: `Check every invariant registered by the modules against the state of the node database,
+at the latest height or the one given with --height, and report each broken invariant with
+its details. Unlike x/crisis, the check doesn't stop at the first broken invariant.`,
+		Example: "rpsd debug check-invariants --height 1200",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			serverCtx := server.GetServerContextFromCmd(cmd)
+
+			height, err := cmd.Flags().GetInt64(flagHeight)
+			if err != nil {
+				return err
+			}
+
+			db, err := dbm.NewDB("application", server.GetAppDBBackend(serverCtx.Viper), filepath.Join(serverCtx.Config.RootDir, "data"))
+			if err != nil {
+				return err
+			}
+
+			rpsApp, err := app.NewRPSApp(serverCtx.Logger, db, nil, false, serverCtx.Viper)
+			if err != nil {
+				return errors.Join(err, db.Close())
+			}
+			// closes the database and the indexer one
+			defer rpsApp.Close()
+
+			if height > 0 {
+				err = rpsApp.LoadHeight(height)
+			} else {
+				err = rpsApp.LoadLatestVersion()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load the state: %w", err)
+			}
+			height = rpsApp.LastBlockHeight()
+
+			ctx := rpsApp.NewUncachedContext(false, cmtproto.Header{ChainID: rpsApp.ChainID(), Height: height})
+
+			results := rpsApp.CheckInvariants(ctx)
+
+			broken := 0
+			for _, res := range results {
+				if !res.Broken {
+					cmd.Printf("ok      %s\n", res.Route)
+					continue
+				}
+
+				broken++
+				cmd.Printf("BROKEN  %s\n%s\n", res.Route, res.Message)
+			}
+
+			if broken > 0 {
+				return fmt.Errorf("%d of %d invariants broken at height %d", broken, len(results), height)
+			}
+
+			cmd.Printf("all %d invariants hold at height %d\n", len(results), height)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64(flagHeight, 0, "Height of the state to check, the latest if 0")
+
+	return cmd
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/server"
 	serverconfig "github.com/cosmos/cosmos-sdk/server/config"
+	simtestutil "github.com/cosmos/cosmos-sdk/testutil/sims"
 	"github.com/cosmos/cosmos-sdk/types/module"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	"github.com/cosmos/cosmos-sdk/x/auth/tx"
@@ -39,10 +40,20 @@ func NewRootCmd() *cobra.Command {
 		clientCtx          client.Context
 	)
 
+	// the x/upgrade keeper reads the upgrade info of the home of the app
+	// options, creating its data directory: the client has no home yet, so
+	// it is given a temporary one
+	tempHome, err := os.MkdirTemp("", "rpsd")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tempHome)
+
 	if err := depinject.Inject(
 		depinject.Configs(app.AppConfig(),
 			depinject.Supply(
 				log.NewNopLogger(),
+				simtestutil.NewAppOptionsWithFlagHome(tempHome),
 			),
 			depinject.Provide(
 				ProvideClientContext,
@@ -57,6 +68,13 @@ func NewRootCmd() *cobra.Command {
 		panic(err)
 	}
 
+	// the IBC modules don't support app wiring, so their client side is
+	// registered manually
+	for name, m := range app.RegisterIBC(clientCtx.InterfaceRegistry) {
+		moduleBasicManager[name] = module.CoreAppModuleBasicAdaptor(name, m)
+		autoCliOpts.Modules[name] = m
+	}
+
 	rootCmd := &cobra.Command{
 		Use:   "rpsd",
 		Short: "rpsd - the Rock, Paper & Scissors app chain",
@@ -93,16 +111,17 @@ func NewRootCmd() *cobra.Command {
 				return err
 			}
 
-			// overwrite the minimum gas price from the app configuration
-			srvCfg := serverconfig.DefaultConfig()
-			srvCfg.MinGasPrices = "0rps"
+			customAppTemplate, customAppConfig := initAppConfig()
+			customCMTConfig := initCometBFTConfig()
 
-			// overwrite the block timeout
-			cmtCfg := cmtcfg.DefaultConfig()
-			cmtCfg.Consensus.TimeoutCommit = 3 * time.Second
-			cmtCfg.LogLevel = "*:error,p2p:info,state:info" // better default logging
+			if err := server.InterceptConfigsPreRunHandler(cmd, customAppTemplate, customAppConfig, customCMTConfig); err != nil {
+				return err
+			}
 
-			return server.InterceptConfigsPreRunHandler(cmd, serverconfig.DefaultConfigTemplate, srvCfg, cmtCfg)
+			// report an invalid [rps] section of app.toml as an error rather
+			// than a panic of the app creation
+			_, err = app.ReadRPSConfig(server.GetServerContextFromCmd(cmd).Viper)
+			return err
 		},
 	}
 
@@ -115,6 +134,34 @@ func NewRootCmd() *cobra.Command {
 	return rootCmd
 }
 
+// initCometBFTConfig helps to override default CometBFT Config values.
+// return cmtcfg.DefaultConfig if no custom configuration is required for the application.
+func initCometBFTConfig() *cmtcfg.Config {
+	cfg := cmtcfg.DefaultConfig()
+
+	// overwrite the block timeout
+	cfg.Consensus.TimeoutCommit = 3 * time.Second
+	cfg.LogLevel = "*:error,p2p:info,state:info" // better default logging
+
+	return cfg
+}
+
+// initAppConfig helps to override default appConfig template and configs.
+// return "", nil if no custom configuration is required for the application.
+func initAppConfig() (string, interface{}) {
+	srvCfg := serverconfig.DefaultConfig()
+
+	// overwrite the minimum gas price from the app configuration
+	srvCfg.MinGasPrices = "0rps"
+
+	customAppConfig := app.CustomAppConfig{
+		Config: *srvCfg,
+		RPS:    app.DefaultRPSConfig(),
+	}
+
+	return serverconfig.DefaultConfigTemplate + app.RPSConfigTemplate, customAppConfig
+}
+
 func ProvideClientContext(
 	appCodec codec.Codec,
 	interfaceRegistry codectypes.InterfaceRegistry,
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/input"
+)
+
+const (
+	flagGameID  = "game-id"
+	flagKeyName = "key"
+	flagAll     = "all"
+)
+
+// RPSCmd returns the command grouping the rps client subcommands.
+func RPSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                        "rps",
+		Short:                      "Rock, Paper & Scissors client subcommands",
+		DisableFlagParsing:         false,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	cmd.AddCommand(SecretsCmd())
+
+	return cmd
+}
+
+// SecretsCmd returns the commands managing the vault of the move secrets.
+func SecretsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the encrypted vault of the pending move secrets",
+		Long: fmt.Sprintf(`Manage the vault of the secrets of the pending moves, stored encrypted with a passphrase
+in %s under the client home, so that a move can still be revealed after a client crash.
+
+The vault is standalone tooling: the chain has no game module yet, so no command commits a
+move and stores its secret. The secrets are added with import and deleted with purge.`, VaultFileName),
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	cmd.AddCommand(
+		secretsListCmd(),
+		secretsExportCmd(),
+		secretsImportCmd(),
+		secretsPurgeCmd(),
+	)
+
+	return cmd
+}
+
+func secretsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the games and keys of the secrets, without the secrets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			vault, err := openVault(cmd, bufio.NewReader(cmd.InOrStdin()), false)
+			if err != nil {
+				return err
+			}
+
+			secrets, err := vault.List()
+			if err != nil {
+				return err
+			}
+
+			for _, secret := range filterSecrets(cmd, secrets) {
+				cmd.Printf("game %d\tkey %s\tcreated %s\n", secret.GameID, secret.KeyName, secret.CreatedAt.Format("2006-01-02 15:04:05"))
+			}
+
+			return nil
+		},
+	}
+
+	addSecretsFilterFlags(cmd)
+
+	return cmd
+}
+
+func secretsExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export the secrets in plain text JSON, to a file or stdout",
+		Long: `Export the secrets in plain text JSON, to back them up or to reveal the moves from another
+client with secrets import. Anyone reading the export can learn the pending moves.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vault, err := openVault(cmd, bufio.NewReader(cmd.InOrStdin()), false)
+			if err != nil {
+				return err
+			}
+
+			secrets, err := vault.List()
+			if err != nil {
+				return err
+			}
+
+			bz, err := json.MarshalIndent(filterSecrets(cmd, secrets), "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				cmd.Println(string(bz))
+				return nil
+			}
+
+			return os.WriteFile(args[0], bz, 0o600)
+		},
+	}
+
+	addSecretsFilterFlags(cmd)
+
+	return cmd
+}
+
+func secretsImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import the secrets of a plain text JSON export",
+		Long: `Import the secrets of a plain text JSON export into the vault, replacing the secrets of
+the same game and key. Nothing is imported unless every secret has a game id, a key name, a move and
+a hex salt.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bz, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			var secrets []MoveSecret
+			if err := json.Unmarshal(bz, &secrets); err != nil {
+				return fmt.Errorf("invalid secrets export %s: %w", args[0], err)
+			}
+
+			vault, err := openVault(cmd, bufio.NewReader(cmd.InOrStdin()), true)
+			if err != nil {
+				return err
+			}
+
+			if err := vault.Put(secrets...); err != nil {
+				return err
+			}
+
+			cmd.Printf("imported %d secrets\n", len(secrets))
+
+			return nil
+		},
+	}
+}
+
+func secretsPurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete the secrets of a game, of a key or all of them",
+		Long: `Delete the secrets of a game with --game-id, of a key with --key, or all of them with --all,
+e.g. those of the settled or abandoned games. A deleted secret can't be revealed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			all, _ := cmd.Flags().GetBool(flagAll)
+			if !all && !cmd.Flags().Changed(flagGameID) && !cmd.Flags().Changed(flagKeyName) {
+				return errors.New("one of --game-id, --key or --all is required")
+			}
+
+			// the passphrase and the confirmation are read from the same input
+			buf := bufio.NewReader(cmd.InOrStdin())
+
+			vault, err := openVault(cmd, buf, false)
+			if err != nil {
+				return err
+			}
+
+			secrets, err := vault.List()
+			if err != nil {
+				return err
+			}
+
+			purged := filterSecrets(cmd, secrets)
+			if len(purged) == 0 {
+				cmd.Println("no secrets to purge")
+				return nil
+			}
+
+			if skip, _ := cmd.Flags().GetBool(flags.FlagSkipConfirmation); !skip {
+				ok, err := input.GetConfirmation(
+					fmt.Sprintf("Delete %d secrets? Their moves can't be revealed anymore.", len(purged)),
+					buf,
+					cmd.ErrOrStderr(),
+				)
+				if err != nil || !ok {
+					return err
+				}
+			}
+
+			n, err := vault.Delete(secretsFilter(cmd))
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("purged %d secrets\n", n)
+
+			return nil
+		},
+	}
+
+	addSecretsFilterFlags(cmd)
+	cmd.Flags().Bool(flagAll, false, "Purge all the secrets")
+	cmd.Flags().BoolP(flags.FlagSkipConfirmation, "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+func addSecretsFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64(flagGameID, 0, "Only the secret of the given game")
+	cmd.Flags().String(flagKeyName, "", "Only the secrets of the given key name")
+}
+
+// secretsFilter returns the filter of the secrets matching the --game-id and
+// --key flags, all of them if unset.
+func secretsFilter(cmd *cobra.Command) func(MoveSecret) bool {
+	gameID, _ := cmd.Flags().GetUint64(flagGameID)
+	filterGame := cmd.Flags().Changed(flagGameID)
+	keyName, _ := cmd.Flags().GetString(flagKeyName)
+
+	return func(secret MoveSecret) bool {
+		return (!filterGame || secret.GameID == gameID) && (keyName == "" || secret.KeyName == keyName)
+	}
+}
+
+func filterSecrets(cmd *cobra.Command, secrets []MoveSecret) []MoveSecret {
+	filter := secretsFilter(cmd)
+
+	filtered := make([]MoveSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		if filter(secret) {
+			filtered = append(filtered, secret)
+		}
+	}
+
+	return filtered
+}
+
+// openVault prompts on buf for the passphrase of the vault of the client
+// home. A vault about to be created asks for the passphrase twice. buf must be
+// the only reader of the command input, as it buffers past the passphrase.
+func openVault(cmd *cobra.Command, buf *bufio.Reader, create bool) (*Vault, error) {
+	clientCtx := client.GetClientContextFromCmd(cmd)
+
+	passphrase, err := input.GetPassword("Enter the vault passphrase:", buf)
+	if err != nil {
+		return nil, err
+	}
+
+	vault := NewVault(clientCtx.HomeDir, passphrase)
+
+	exists, err := vault.Exists()
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists && create {
+		repeated, err := input.GetPassword("Repeat the vault passphrase:", buf)
+		if err != nil {
+			return nil, err
+		}
+
+		if repeated != passphrase {
+			return nil, errors.New("passphrases don't match")
+		}
+	}
+
+	return vault, nil
+}
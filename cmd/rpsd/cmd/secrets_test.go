@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/client"
+)
+
+func TestSecretsPurgeCmd(t *testing.T) {
+	home := t.TempDir()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, NewVault(home, "password1").Put(
+		MoveSecret{GameID: 1, KeyName: "alice", Move: "rock", Salt: "aa", CreatedAt: createdAt},
+		MoveSecret{GameID: 2, KeyName: "bob", Move: "paper", Salt: "bb", CreatedAt: createdAt},
+	))
+
+	testCases := []struct {
+		name      string
+		input     string
+		expPurged int
+	}{
+		{"declined", "password1\nn\n", 0},
+		// the passphrase and the confirmation are read from the same input
+		{"confirmed", "password1\ny\n", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := secretsPurgeCmd()
+			cmd.SetArgs([]string{"--all"})
+			cmd.SetIn(strings.NewReader(tc.input))
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			cmd.SetContext(context.Background())
+			require.NoError(t, client.SetCmdClientContext(cmd, client.Context{HomeDir: home}))
+			require.NoError(t, cmd.Execute())
+
+			secrets, err := NewVault(home, "password1").List()
+			require.NoError(t, err)
+			require.Len(t, secrets, 2-tc.expPurged)
+		})
+	}
+}
+
+func TestSecretsImportCmd(t *testing.T) {
+	home := t.TempDir()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, NewVault(home, "password1").Put(
+		MoveSecret{GameID: 1, KeyName: "alice", Move: "rock", Salt: "aa", CreatedAt: createdAt},
+	))
+
+	testCases := []struct {
+		name    string
+		export  string
+		errMsg  string
+		secrets int
+	}{
+		{"no game id", `[{"key_name":"bob","move":"paper","salt":"bb"}]`, `secret of key "bob" has no game id`, 1},
+		{"no move", `[{"game_id":2,"key_name":"bob","salt":"bb"}]`, `secret of game 2 and key "bob" has no move`, 1},
+		{"salt not hex", `[{"game_id":2,"key_name":"bob","move":"paper","salt":"bob"}]`, "salt is not hex", 1},
+		// a single invalid secret fails the whole import
+		{"valid and invalid", `[{"game_id":2,"key_name":"bob","move":"paper","salt":"bb"},{"game_id":3,"key_name":"bob","move":"paper"}]`, "has no salt", 1},
+		{"valid", `[{"game_id":2,"key_name":"bob","move":"paper","salt":"bb"}]`, "", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exportPath := filepath.Join(t.TempDir(), "secrets.json")
+			require.NoError(t, os.WriteFile(exportPath, []byte(tc.export), 0o600))
+
+			cmd := secretsImportCmd()
+			cmd.SetArgs([]string{exportPath})
+			cmd.SetIn(strings.NewReader("password1\n"))
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			cmd.SetContext(context.Background())
+			require.NoError(t, client.SetCmdClientContext(cmd, client.Context{HomeDir: home}))
+
+			err := cmd.Execute()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.errMsg)
+			}
+
+			secrets, err := NewVault(home, "password1").List()
+			require.NoError(t, err)
+			require.Len(t, secrets, tc.secrets)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// VaultFileName is the file name of the secrets vault in the client home.
+const VaultFileName = "rps_secrets.json"
+
+// vaultVersion is the version of the vault file format.
+const vaultVersion = 1
+
+// the key derivation parameters of the vault, those of the sdk armored keys
+const (
+	vaultArgon2Time    = 1
+	vaultArgon2Memory  = 64 * 1024
+	vaultArgon2Threads = 4
+	vaultSaltSize      = 16
+)
+
+// ErrInvalidVaultPassphrase is returned when the vault can't be decrypted.
+var ErrInvalidVaultPassphrase = errors.New("invalid vault passphrase")
+
+// MoveSecret is the secret of a committed move, revealed once the opponent
+// has committed too.
+type MoveSecret struct {
+	GameID    uint64    `json:"game_id"`
+	KeyName   string    `json:"key_name"`
+	Move      string    `json:"move"`
+	Salt      string    `json:"salt"` // hex
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks that the secret identifies a game move and holds a hex salt.
+func (s MoveSecret) Validate() error {
+	if s.GameID == 0 {
+		return fmt.Errorf("secret of key %q has no game id", s.KeyName)
+	}
+
+	if s.KeyName == "" {
+		return fmt.Errorf("secret of game %d has no key name", s.GameID)
+	}
+
+	if s.Move == "" {
+		return fmt.Errorf("secret of game %d and key %q has no move", s.GameID, s.KeyName)
+	}
+
+	if s.Salt == "" {
+		return fmt.Errorf("secret of game %d and key %q has no salt", s.GameID, s.KeyName)
+	}
+
+	if _, err := hex.DecodeString(s.Salt); err != nil {
+		return fmt.Errorf("secret of game %d and key %q: salt is not hex: %w", s.GameID, s.KeyName, err)
+	}
+
+	return nil
+}
+
+// vaultFile is the content of the vault file: the secrets are encrypted as a
+// whole, so that the game ids and key names aren't readable either.
+type vaultFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Vault is the encrypted file holding the secrets of the pending moves of
+// the client keys, keyed by game id and key name, so that they survive a
+// client crash until the game is settled.
+type Vault struct {
+	path       string
+	passphrase string
+}
+
+// NewVault returns the vault of the client home, encrypted with passphrase.
+// The vault file is created on the first write.
+func NewVault(home, passphrase string) *Vault {
+	return &Vault{
+		path:       filepath.Join(home, VaultFileName),
+		passphrase: passphrase,
+	}
+}
+
+// Exists returns true if the vault file exists.
+func (v *Vault) Exists() (bool, error) {
+	_, err := os.Stat(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// List returns the secrets of the vault, ordered by game id and key name.
+func (v *Vault) List() ([]MoveSecret, error) {
+	bz, err := os.ReadFile(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(bz, &file); err != nil {
+		return nil, fmt.Errorf("failed to read vault %s: %w", v.path, err)
+	}
+
+	if file.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported vault version %d", file.Version)
+	}
+
+	aead, err := chacha20poly1305.NewX(v.key(file.Salt))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidVaultPassphrase
+	}
+
+	var secrets []MoveSecret
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secrets: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// Get returns the secret of the game move of the key.
+func (v *Vault) Get(gameID uint64, keyName string) (MoveSecret, bool, error) {
+	secrets, err := v.List()
+	if err != nil {
+		return MoveSecret{}, false, err
+	}
+
+	for _, secret := range secrets {
+		if secret.GameID == gameID && secret.KeyName == keyName {
+			return secret, true, nil
+		}
+	}
+
+	return MoveSecret{}, false, nil
+}
+
+// Put adds the secrets to the vault, replacing those of the same game id and
+// key name. Nothing is written if any of them is invalid.
+func (v *Vault) Put(secrets ...MoveSecret) error {
+	for _, secret := range secrets {
+		if err := secret.Validate(); err != nil {
+			return err
+		}
+	}
+
+	current, err := v.List()
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets {
+		current = removeSecrets(current, func(s MoveSecret) bool {
+			return s.GameID == secret.GameID && s.KeyName == secret.KeyName
+		})
+		current = append(current, secret)
+	}
+
+	return v.write(current)
+}
+
+// Delete removes the secrets matching the filter and returns their number.
+func (v *Vault) Delete(filter func(MoveSecret) bool) (int, error) {
+	current, err := v.List()
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := removeSecrets(current, filter)
+	if len(remaining) == len(current) {
+		return 0, nil
+	}
+
+	return len(current) - len(remaining), v.write(remaining)
+}
+
+// write encrypts the secrets with a new salt and replaces the vault file.
+func (v *Vault) write(secrets []MoveSecret) error {
+	sort.Slice(secrets, func(i, j int) bool {
+		if secrets[i].GameID != secrets[j].GameID {
+			return secrets[i].GameID < secrets[j].GameID
+		}
+		return secrets[i].KeyName < secrets[j].KeyName
+	})
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	file := vaultFile{
+		Version: vaultVersion,
+		Salt:    make([]byte, vaultSaltSize),
+		Nonce:   make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+
+	aead, err := chacha20poly1305.NewX(v.key(file.Salt))
+	if err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, nil)
+
+	bz, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0o700); err != nil {
+		return err
+	}
+
+	// write then rename, so that a crash never leaves a truncated vault
+	tmp := v.path + ".tmp"
+	if err := os.WriteFile(tmp, bz, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, v.path)
+}
+
+func (v *Vault) key(salt []byte) []byte {
+	return argon2.IDKey([]byte(v.passphrase), salt, vaultArgon2Time, vaultArgon2Memory, vaultArgon2Threads, chacha20poly1305.KeySize)
+}
+
+// removeSecrets returns the secrets not matching the filter.
+func removeSecrets(secrets []MoveSecret, filter func(MoveSecret) bool) []MoveSecret {
+	remaining := make([]MoveSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		if !filter(secret) {
+			remaining = append(remaining, secret)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVault(t *testing.T) {
+	home := t.TempDir()
+	vault := NewVault(home, "passphrase")
+
+	secrets, err := vault.List()
+	require.NoError(t, err)
+	require.Empty(t, secrets)
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, vault.Put(
+		MoveSecret{GameID: 2, KeyName: "alice", Move: "rock", Salt: "aa", CreatedAt: createdAt},
+		MoveSecret{GameID: 1, KeyName: "bob", Move: "paper", Salt: "bb", CreatedAt: createdAt},
+		MoveSecret{GameID: 1, KeyName: "alice", Move: "scissors", Salt: "cc", CreatedAt: createdAt},
+	))
+
+	// the secrets aren't readable in the vault file
+	bz, err := os.ReadFile(filepath.Join(home, VaultFileName))
+	require.NoError(t, err)
+	require.NotContains(t, string(bz), "scissors")
+	require.NotContains(t, string(bz), "alice")
+
+	_, err = NewVault(home, "wrong").List()
+	require.ErrorIs(t, err, ErrInvalidVaultPassphrase)
+
+	// secrets are replaced by game id and key name
+	require.NoError(t, vault.Put(MoveSecret{GameID: 1, KeyName: "alice", Move: "rock", Salt: "dd", CreatedAt: createdAt}))
+
+	secret, found, err := NewVault(home, "passphrase").Get(1, "alice")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "rock", secret.Move)
+	require.Equal(t, "dd", secret.Salt)
+
+	secrets, err = vault.List()
+	require.NoError(t, err)
+	require.Len(t, secrets, 3)
+	require.Equal(t, uint64(1), secrets[0].GameID)
+	require.Equal(t, "alice", secrets[0].KeyName)
+
+	n, err := vault.Delete(func(s MoveSecret) bool { return s.GameID == 1 })
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	_, found, err = vault.Get(1, "bob")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	_, found, err = vault.Get(2, "alice")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.Error(t, vault.Put(MoveSecret{GameID: 3}))
+}
+
+func TestMoveSecretValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		secret MoveSecret
+		errMsg string
+	}{
+		{"valid", MoveSecret{GameID: 1, KeyName: "alice", Move: "rock", Salt: "0aff"}, ""},
+		{"no game id", MoveSecret{KeyName: "alice", Move: "rock", Salt: "0aff"}, `secret of key "alice" has no game id`},
+		{"no key name", MoveSecret{GameID: 1, Move: "rock", Salt: "0aff"}, "secret of game 1 has no key name"},
+		{"no move", MoveSecret{GameID: 1, KeyName: "alice", Salt: "0aff"}, `secret of game 1 and key "alice" has no move`},
+		{"no salt", MoveSecret{GameID: 1, KeyName: "alice", Move: "rock"}, `secret of game 1 and key "alice" has no salt`},
+		{"salt not hex", MoveSecret{GameID: 1, KeyName: "alice", Move: "rock", Salt: "salt"}, "salt is not hex"},
+		{"odd length salt", MoveSecret{GameID: 1, KeyName: "alice", Move: "rock", Salt: "abc"}, "salt is not hex"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.secret.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}